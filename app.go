@@ -2,6 +2,7 @@ package gmvc
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"path"
 	"strings"
@@ -15,6 +16,9 @@ type App struct {
 	View            View
 	SessionProvider SessionProvider
 	ErrorHandler    ErrorHandler
+	RedirectHosts   []string
+	MaxBodySize     int64
+	ErrorLog        *log.Logger
 }
 
 func NewApp() *App {
@@ -30,6 +34,10 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c := a.buildContext(w, r)
 	urlpath := r.URL.Path
 
+	if a.MaxBodySize > 0 {
+		c.LimitBody(a.MaxBodySize)
+	}
+
 	if a.Path != "/" {
 		if !strings.HasPrefix(urlpath, a.Path) {
 			defer c.finalize()
@@ -56,19 +64,35 @@ func (a *App) dispatch(c *Context, urlpath string) {
 }
 
 func (a *App) buildContext(w http.ResponseWriter, r *http.Request) *Context {
-	return &Context{
+	rw := &responseWriter{ResponseWriter: w}
+	c := &Context{
 		Request:         r,
-		ResponseWriter:  w,
+		ResponseWriter:  rw,
 		Path:            a.Path,
 		Vars:            make(PathVars),
 		Attrs:           make(Attrs),
 		View:            a.View,
 		app:             a,
 		request:         r,
-		response:        w,
+		response:        rw,
 		sessionProvider: a.SessionProvider,
 		errorHandler:    a.ErrorHandler,
 	}
+	rw.beforeCommit = c.commitSession
+	return c
+}
+
+// logf logs the errors which can't be replied, e.g. the session can't be
+// saved after the response is written.
+func (a *App) logf(format string, v ...interface{}) {
+	if a == nil {
+		return
+	}
+	if a.ErrorLog != nil {
+		a.ErrorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
 }
 
 type AppAttrs struct {
@@ -79,6 +103,9 @@ type AppAttrs struct {
 func (a *AppAttrs) Set(key string, value interface{}) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
+	if a.values == nil {
+		a.values = make(map[string]interface{})
+	}
 	a.values[key] = value
 }
 
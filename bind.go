@@ -0,0 +1,215 @@
+package gmvc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var (
+	typeOfFileHeader  reflect.Type = reflect.TypeOf(new(multipart.FileHeader))
+	typeOfFileHeaders              = reflect.TypeOf([]*multipart.FileHeader{})
+)
+
+type BindError struct {
+	Field string
+	Key   string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("bind field %s from '%s': %v", e.Field, e.Key, e.Err)
+}
+
+func (e *BindError) Status() int {
+	return http.StatusBadRequest
+}
+
+func (c *Context) Bind(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("bind destination must be a non-nil pointer, got %T", dst)
+	}
+
+	var binders []*binder
+
+	mediatype := ""
+	if ct := c.Request.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return NewStatusError(http.StatusBadRequest, fmt.Errorf("malformed content type: %v", err))
+		}
+		mediatype = mt
+	}
+
+	switch {
+	case mediatype == "application/json" || strings.HasSuffix(mediatype, "+json"):
+		if err := c.decodeBody(json.NewDecoder(c.Request.Body), dst); err != nil {
+			return err
+		}
+
+	case mediatype == "application/xml" || mediatype == "text/xml" || strings.HasSuffix(mediatype, "+xml"):
+		if err := c.decodeBody(xml.NewDecoder(c.Request.Body), dst); err != nil {
+			return err
+		}
+
+	case mediatype == "multipart/form-data":
+		f, err := c.MultipartForm(0)
+		if err != nil {
+			return err
+		}
+		form, err := c.Form()
+		if err != nil {
+			return err
+		}
+		binders = append(binders, &binder{tag: "form", values: form, files: f.Files})
+
+	default:
+		form, err := c.Form()
+		if err != nil {
+			return err
+		}
+		binders = append(binders, &binder{tag: "form", values: form})
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		if len(binders) > 0 {
+			return fmt.Errorf("bind destination must be a pointer to struct, got %T", dst)
+		}
+		return Validate(dst)
+	}
+
+	query, err := c.Query()
+	if err != nil {
+		return err
+	}
+
+	binders = append(binders,
+		&binder{tag: "query", values: query},
+		&binder{tag: "path", values: c.Vars.values()},
+	)
+
+	for _, b := range binders {
+		if _, err := b.bind(rv, ""); err != nil {
+			return err
+		}
+	}
+
+	return Validate(dst)
+}
+
+type decoder interface {
+	Decode(v interface{}) error
+}
+
+func (c *Context) decodeBody(d decoder, dst interface{}) error {
+	if c.Request.Body == nil {
+		return nil
+	}
+	if err := d.Decode(dst); err != nil && err != io.EOF {
+		return c.decodeError(err)
+	}
+	return nil
+}
+
+type binder struct {
+	tag    string
+	values map[string][]string
+	files  map[string][]*multipart.FileHeader
+}
+
+func (b *binder) bind(v reflect.Value, prefix string) (bool, error) {
+	t := v.Type()
+	bound := false
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		name := sf.Tag.Get(b.tag)
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		key := prefix + name
+
+		if sf.Type == typeOfFileHeader || sf.Type == typeOfFileHeaders {
+			fhs, ok := b.files[key]
+			if name == "" || !ok || len(fhs) == 0 {
+				continue
+			}
+			if sf.Type == typeOfFileHeader {
+				fv.Set(reflect.ValueOf(fhs[0]))
+			} else {
+				fv.Set(reflect.ValueOf(fhs))
+			}
+			bound = true
+			continue
+		}
+
+		if isNestedStruct(sf.Type) {
+			if name != "" {
+				key += "."
+			}
+			ok, err := b.bindNested(fv, key)
+			if err != nil {
+				return bound, err
+			}
+			bound = bound || ok
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		ss, ok := b.values[key]
+		if !ok || len(ss) == 0 {
+			continue
+		}
+
+		if err := setValue(fv, ss, sf.Tag.Get("layout")); err != nil {
+			return bound, &BindError{Field: sf.Name, Key: key, Err: err}
+		}
+		bound = true
+	}
+
+	return bound, nil
+}
+
+func (b *binder) bindNested(v reflect.Value, prefix string) (bool, error) {
+	if v.Kind() != reflect.Ptr {
+		return b.bind(v, prefix)
+	}
+
+	if !v.IsNil() {
+		return b.bind(v.Elem(), prefix)
+	}
+
+	nv := reflect.New(v.Type().Elem())
+	ok, err := b.bind(nv.Elem(), prefix)
+	if ok {
+		v.Set(nv)
+	}
+	return ok, err
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == typeOfTime {
+		return false
+	}
+	return !reflect.PtrTo(t).Implements(typeOfTextUnmarshaler)
+}
@@ -0,0 +1,167 @@
+package gmvc
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindAddress struct {
+	City string `form:"city" json:"city"`
+}
+
+type bindItem struct {
+	Id      int                   `path:"id"`
+	Name    string                `form:"name" json:"name" xml:"name"`
+	Tags    []string              `form:"tag" json:"tags"`
+	Count   *int                  `form:"count" json:"count"`
+	Day     time.Time             `form:"day" layout:"2006-01-02"`
+	Page    int                   `query:"page"`
+	Address bindAddress           `form:"addr" json:"address"`
+	Home    *bindAddress          `form:"home"`
+	File    *multipart.FileHeader `form:"file"`
+	Skip    string                `form:"-"`
+}
+
+func serveBind(t *testing.T, r *http.Request) (*bindItem, error) {
+	t.Helper()
+
+	var item bindItem
+	var err error
+	app := NewApp()
+	app.Router.HandleFunc("/items/{id}", func(c *Context) error {
+		err = c.Bind(&item)
+		return nil
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), r)
+	return &item, err
+}
+
+func newFormRequest(target string, contentType string, body string) *http.Request {
+	r := httptest.NewRequest("POST", target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestBind(t *testing.T) {
+	count := 3
+	day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        bindItem
+	}{
+		{
+			"form",
+			"application/x-www-form-urlencoded",
+			"name=pen&tag=a&tag=b&count=3&day=2024-05-06&addr.city=Paris&home.city=Rome&Skip=x",
+			bindItem{Id: 7, Name: "pen", Tags: []string{"a", "b"}, Count: &count, Day: day, Page: 2,
+				Address: bindAddress{City: "Paris"}, Home: &bindAddress{City: "Rome"}},
+		},
+		{
+			"form without nested values",
+			"application/x-www-form-urlencoded; charset=utf-8",
+			"name=pen",
+			bindItem{Id: 7, Name: "pen", Page: 2},
+		},
+		{
+			"json",
+			"application/json",
+			`{"name":"pen","tags":["a"],"count":3,"address":{"city":"Paris"}}`,
+			bindItem{Id: 7, Name: "pen", Tags: []string{"a"}, Count: &count, Page: 2, Address: bindAddress{City: "Paris"}},
+		},
+		{
+			"json suffix",
+			"application/vnd.api+json",
+			`{"name":"pen"}`,
+			bindItem{Id: 7, Name: "pen", Page: 2},
+		},
+		{
+			"empty json body",
+			"application/json",
+			"",
+			bindItem{Id: 7, Page: 2},
+		},
+		{
+			"xml",
+			"application/xml",
+			`<bindItem><name>pen</name></bindItem>`,
+			bindItem{Id: 7, Name: "pen", Page: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := serveBind(t, newFormRequest("/items/7?page=2", tt.contentType, tt.body))
+		if err != nil {
+			t.Errorf("%s: Bind: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("%s: Bind = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	mw.WriteField("name", "pen")
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	fw.Write([]byte("hello"))
+	mw.Close()
+
+	got, err := serveBind(t, newFormRequest("/items/1", mw.FormDataContentType(), buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "pen" || got.File == nil || got.File.Filename != "a.txt" || got.File.Size != 5 {
+		t.Errorf("Bind = %+v, want name and file", *got)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"bad int", "/items/x", "", "", http.StatusBadRequest},
+		{"bad form value", "/items/1", "application/x-www-form-urlencoded", "count=abc", http.StatusBadRequest},
+		{"bad time", "/items/1", "application/x-www-form-urlencoded", "day=06/05/2024", http.StatusBadRequest},
+		{"bad query", "/items/1?page=x", "", "", http.StatusBadRequest},
+		{"malformed json", "/items/1", "application/json", `{"name":`, http.StatusBadRequest},
+		{"malformed content type", "/items/1", "text/;;", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		_, err := serveBind(t, newFormRequest(tt.target, tt.contentType, tt.body))
+		var se StatusError
+		if !errors.As(err, &se) || se.Status() != tt.status {
+			t.Errorf("%s: Bind returns %v, want status %d", tt.name, err, tt.status)
+		}
+	}
+}
+
+func TestBindDestination(t *testing.T) {
+	c := &Context{Request: httptest.NewRequest("GET", "/", nil)}
+
+	var item bindItem
+	for _, dst := range []interface{}{nil, item, (*bindItem)(nil)} {
+		if err := c.Bind(dst); err == nil {
+			t.Errorf("Bind(%T) returns no error", dst)
+		}
+	}
+}
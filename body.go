@@ -0,0 +1,146 @@
+package gmvc
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type statusError struct {
+	status int
+	err    error
+}
+
+func NewStatusError(status int, err error) StatusError {
+	return &statusError{status: status, err: err}
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Status() int {
+	return e.status
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func BodyLimit(n int64) Filter {
+	if n <= 0 {
+		panic(fmt.Sprintf("gmvc: bad body limit %d", n))
+	}
+
+	return FilterFunc(func(fc *FilterContext) error {
+		fc.Context.setBodyLimit(n)
+		return fc.Next()
+	})
+}
+
+// LimitBody lowers the body limit of the request, a limit n <= 0 or
+// above the current one is ignored. Use BodyLimit to raise the limit
+// of App.MaxBodySize on a route.
+func (c *Context) LimitBody(n int64) {
+	if n > 0 && (c.bodyLimit <= 0 || n < c.bodyLimit) {
+		c.setBodyLimit(n)
+	}
+}
+
+func (c *Context) setBodyLimit(n int64) {
+	if c.Request.Body == nil {
+		return
+	}
+	if c.body == nil {
+		c.body = c.Request.Body
+	}
+
+	// MaxBytesReader tells the server to close the connection through an
+	// unexported method, which only the server's own writer has.
+	w := c.response
+	if rw, ok := w.(*responseWriter); ok {
+		w = rw.ResponseWriter
+	}
+	c.Request.Body = http.MaxBytesReader(w, c.body, n)
+	c.bodyLimit = n
+}
+
+func (c *Context) DecodeJSON(dst interface{}) error {
+	if c.Request.Body == nil {
+		return NewStatusError(http.StatusBadRequest, errors.New("empty request body"))
+	}
+
+	d := json.NewDecoder(c.Request.Body)
+	d.DisallowUnknownFields()
+
+	if err := d.Decode(dst); err != nil {
+		return c.decodeError(err)
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return c.decodeError(errors.New("unexpected data after json value"))
+	}
+
+	return nil
+}
+
+func (c *Context) DecodeXML(dst interface{}) error {
+	if c.Request.Body == nil {
+		return NewStatusError(http.StatusBadRequest, errors.New("empty request body"))
+	}
+
+	d := xml.NewDecoder(c.Request.Body)
+	d.Strict = true
+
+	if err := d.Decode(dst); err != nil {
+		return c.decodeError(err)
+	}
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c.decodeError(err)
+		}
+		switch t := t.(type) {
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return c.decodeError(errors.New("unexpected data after xml element"))
+			}
+		case xml.Comment, xml.ProcInst:
+		default:
+			return c.decodeError(errors.New("unexpected data after xml element"))
+		}
+	}
+
+	return nil
+}
+
+func (c *Context) decodeError(err error) error {
+	if err == io.EOF {
+		err = errors.New("empty request body")
+	}
+	if e := bodyError(err); e != err {
+		return e
+	}
+	return NewStatusError(http.StatusBadRequest, fmt.Errorf("malformed request body: %v", err))
+}
+
+func bodyError(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return NewStatusError(http.StatusRequestEntityTooLarge, fmt.Errorf("request body too large, limit %d bytes", mbe.Limit))
+	}
+	return err
+}
+
+func formError(err error) error {
+	if e := bodyError(err); e != err {
+		return e
+	}
+	return NewStatusError(http.StatusBadRequest, err)
+}
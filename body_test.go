@@ -0,0 +1,92 @@
+package gmvc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		limit  int64
+		body   string
+		status int
+	}{
+		{"app limit", nil, 0, "name=abcdefgh", http.StatusRequestEntityTooLarge},
+		{"within app limit", nil, 0, "name=abc", http.StatusOK},
+		{"route raises", BodyLimit(100), 0, "name=abcdefgh", http.StatusOK},
+		{"route lowers", BodyLimit(4), 0, "name=abc", http.StatusRequestEntityTooLarge},
+		{"context lowers", nil, 4, "name=abc", http.StatusRequestEntityTooLarge},
+		{"context can not raise", nil, 100, "name=abcdefgh", http.StatusRequestEntityTooLarge},
+		{"context ignores zero", nil, -1, "name=abcdefgh", http.StatusRequestEntityTooLarge},
+		{"malformed form", nil, 0, "name=%zz", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		app := NewApp()
+		app.MaxBodySize = 10
+		if tt.filter != nil {
+			app.Router.Filter("/**", tt.filter)
+		}
+		app.Router.HandleFunc("/form", func(c *Context) error {
+			if tt.limit != 0 {
+				c.LimitBody(tt.limit)
+			}
+			_, err := c.PostForm()
+			return err
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, newFormRequest("/form", "application/x-www-form-urlencoded", tt.body))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
+
+func TestBadBodyLimit(t *testing.T) {
+	for _, n := range []int64{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("BodyLimit(%d) does not panic", n)
+				}
+			}()
+			BodyLimit(n)
+		}()
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"name":"pen"}`, http.StatusOK},
+		{"empty", "", http.StatusBadRequest},
+		{"unknown field", `{"name":"pen","x":1}`, http.StatusBadRequest},
+		{"trailing data", `{"name":"pen"} {}`, http.StatusBadRequest},
+		{"too large", `{"name":"` + strings.Repeat("a", 100) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		app := NewApp()
+		app.MaxBodySize = 64
+		app.Router.HandleFunc("/json", func(c *Context) error {
+			var v struct {
+				Name string `json:"name"`
+			}
+			return c.DecodeJSON(&v)
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, newFormRequest("/json", "application/json", tt.body))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package gmvc
 import (
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 )
 
 const (
@@ -25,7 +28,10 @@ type Context struct {
 	parent          *Context
 	request         *http.Request
 	response        http.ResponseWriter
+	body            io.ReadCloser
+	bodyLimit       int64
 	form            Values
+	query           Values
 	multipartForm   *MultipartForm
 	session         Session
 	sessionProvider SessionProvider
@@ -41,12 +47,40 @@ func (c *Context) Form() (Values, error) {
 		return c.form, nil
 	}
 	if err := c.Request.ParseForm(); err != nil {
-		return nil, err
+		return nil, formError(err)
 	}
 	c.form = Values(c.Request.Form)
 	return c.form, nil
 }
 
+func (c *Context) Query() (Values, error) {
+	if c.query != nil {
+		return c.query, nil
+	}
+	q, err := url.ParseQuery(c.Request.URL.RawQuery)
+	if err != nil {
+		return nil, NewStatusError(http.StatusBadRequest, err)
+	}
+	c.query = Values(q)
+	return c.query, nil
+}
+
+func (c *Context) PostForm() (Values, error) {
+	ct := c.Request.Header.Get("Content-Type")
+	if mt, _, _ := mime.ParseMediaType(ct); mt == "multipart/form-data" {
+		if _, err := c.MultipartForm(0); err != nil {
+			return nil, err
+		}
+	} else if _, err := c.Form(); err != nil {
+		return nil, err
+	}
+
+	if c.Request.PostForm == nil {
+		return make(Values), nil
+	}
+	return Values(c.Request.PostForm), nil
+}
+
 func (c *Context) MultipartForm(maxMemory int64) (*MultipartForm, error) {
 	if c.multipartForm != nil {
 		return c.multipartForm, nil
@@ -62,7 +96,7 @@ func (c *Context) MultipartForm(maxMemory int64) (*MultipartForm, error) {
 		maxMemory = defaultMaxMemory
 	}
 	if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
-		return nil, err
+		return nil, formError(err)
 	}
 
 	if f := c.Request.MultipartForm; f != nil {
@@ -138,17 +172,93 @@ func (c *Context) Include(urlpath string) error {
 }
 
 func (c *Context) Redirect(urlstr string, code int) error {
-	u, err := url.Parse(urlstr)
+	u, err := c.resolveRedirect(urlstr)
 	if err != nil {
 		return err
 	}
 
-	if !u.IsAbs() {
-		urlstr = path.Join(path.Join("/", c.Path, urlstr))
+	http.Redirect(c.ResponseWriter, c.Request, u.String(), code)
+	return nil
+}
+
+func (c *Context) RedirectRoute(name string, vars PathVars, code int) error {
+	urlpath, err := c.app.Router.URL(name, vars)
+	if err != nil {
+		return err
 	}
+	return c.Redirect(urlpath, code)
+}
 
-	http.Redirect(c.ResponseWriter, c.Request, urlstr, code)
-	return nil
+func (c *Context) RedirectBack(fallback string, code int) error {
+	if ref := c.Request.Referer(); ref != "" {
+		if u, err := url.Parse(ref); err == nil && u.Host != "" && c.allowRedirect(u) {
+			http.Redirect(c.ResponseWriter, c.Request, u.String(), code)
+			return nil
+		}
+	}
+	return c.Redirect(fallback, code)
+}
+
+func (c *Context) resolveRedirect(urlstr string) (*url.URL, error) {
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case u.Scheme != "" || u.Host != "":
+		if !c.allowRedirect(u) {
+			return nil, fmt.Errorf("redirect to foreign url '%s' is not allowed", urlstr)
+		}
+		return u, nil
+
+	case strings.HasPrefix(u.Path, "/"):
+		p := path.Join("/", c.Path, u.Path)
+		if strings.HasSuffix(u.Path, "/") && p != "/" {
+			p += "/"
+		}
+		u.Path = p
+		u.RawPath = ""
+
+	default:
+		u = c.Request.URL.ResolveReference(u)
+		u.Scheme = ""
+		u.Host = ""
+		u.User = nil
+	}
+
+	if strings.HasPrefix(u.Path, "//") || strings.HasPrefix(u.Path, "/\\") {
+		return nil, fmt.Errorf("redirect to ambiguous path '%s' is not allowed", urlstr)
+	}
+
+	return u, nil
+}
+
+func (c *Context) allowRedirect(u *url.URL) bool {
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Host)
+	if host == strings.ToLower(c.Request.Host) {
+		return true
+	}
+
+	if c.app == nil {
+		return false
+	}
+
+	for _, h := range c.app.RedirectHosts {
+		h = strings.ToLower(h)
+		if h == host {
+			return true
+		}
+		if strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:]) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (c *Context) Render(name string, value interface{}) error {
@@ -169,7 +279,11 @@ func (c *Context) Status(status int) {
 }
 
 func (c *Context) Error(err error) {
-	c.ErrorStatus(err, http.StatusInternalServerError)
+	status := http.StatusInternalServerError
+	if se, ok := err.(StatusError); ok {
+		status = se.Status()
+	}
+	c.ErrorStatus(err, status)
 }
 
 func (c *Context) ErrorStatus(err error, status int) {
@@ -182,12 +296,37 @@ func (c *Context) ErrorStatus(err error, status int) {
 	}
 }
 
+// finalize saves the session at the end of the request. If nothing is
+// written yet, it commits the response, so a failure still reaches the
+// ErrorHandler.
 func (c *Context) finalize() {
-	if c.parent == nil {
-		if c.session != nil && c.session.Valid() {
-			c.session.Save()
-		}
+	if c.parent != nil {
+		return
+	}
+	if rw, ok := c.response.(*responseWriter); ok && !rw.committed {
+		rw.commit()
+		return
+	}
+	if err := c.saveSession(); err != nil {
+		c.app.logf("gmvc: save session: %v", err)
+	}
+}
+
+// commitSession saves the session before the response headers are
+// written, on failure the error response is written instead.
+func (c *Context) commitSession() error {
+	err := c.saveSession()
+	if err != nil {
+		c.Error(err)
+	}
+	return err
+}
+
+func (c *Context) saveSession() error {
+	if c.session == nil || !c.session.Valid() {
+		return nil
 	}
+	return c.session.Save()
 }
 
 type Attrs map[string]interface{}
@@ -1,21 +1,30 @@
 package controllers
 
 import (
+	"encoding"
+	"fmt"
 	"github.com/hujh/gmvc"
 	"io"
 	"net/http"
 	"reflect"
+	"time"
 )
 
 var (
-	typeOfRequest        reflect.Type = reflect.TypeOf(new(http.Request))
-	typeOfContext                     = reflect.TypeOf(new(gmvc.Context))
-	typeOfPathVars                    = reflect.TypeOf(gmvc.PathVars{})
-	typeOfValues                      = reflect.TypeOf(gmvc.Values{})
-	typeOfMultipartForm               = reflect.TypeOf(gmvc.MultipartForm{})
-	typeOfResponseWriter              = reflect.TypeOf(new(http.ResponseWriter)).Elem()
-	typeOfReadCloser                  = reflect.TypeOf(new(io.ReadCloser)).Elem()
-	typeOfWriter                      = reflect.TypeOf(new(io.Writer)).Elem()
+	typeOfRequest            reflect.Type = reflect.TypeOf(new(http.Request))
+	typeOfContext                         = reflect.TypeOf(new(gmvc.Context))
+	typeOfPathVars                        = reflect.TypeOf(gmvc.PathVars{})
+	typeOfValues                          = reflect.TypeOf(gmvc.Values{})
+	typeOfQueryValues                     = reflect.TypeOf(gmvc.QueryValues{})
+	typeOfPostFormValues                  = reflect.TypeOf(gmvc.PostFormValues{})
+	typeOfMultipartForm                   = reflect.TypeOf(new(gmvc.MultipartForm))
+	typeOfMultipartFormValue              = typeOfMultipartForm.Elem()
+	typeOfSession                         = reflect.TypeOf(new(gmvc.Session)).Elem()
+	typeOfResponseWriter                  = reflect.TypeOf(new(http.ResponseWriter)).Elem()
+	typeOfReadCloser                      = reflect.TypeOf(new(io.ReadCloser)).Elem()
+	typeOfWriter                          = reflect.TypeOf(new(io.Writer)).Elem()
+	typeOfTime                            = reflect.TypeOf(time.Time{})
+	typeOfTextUnmarshaler                 = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
 )
 
 var (
@@ -24,10 +33,14 @@ var (
 		&contextArgument{},
 		&pathVarsArgument{},
 		&valuesArgument{},
+		&queryValuesArgument{},
+		&postFormValuesArgument{},
 		&multipartFormArgument{},
+		&multipartFormValueArgument{},
 		&responseWriterArgument{},
 		&readCloserArgument{},
 		&writerArgument{},
+		&sessionArgument{},
 	}
 )
 
@@ -36,16 +49,119 @@ type argument interface {
 	Get(c *gmvc.Context) (reflect.Value, error)
 }
 
-type zeroArgument struct {
+func resolveArgument(t reflect.Type, resolvers []ArgumentResolver) argument {
+	for _, r := range resolvers {
+		if r.Supports(t) {
+			return &resolverArgument{resolver: r, t: t}
+		}
+	}
+	return builtinArgument(t)
+}
+
+func builtinArgument(t reflect.Type) argument {
+	for _, a := range arguments {
+		at := a.Type()
+		if (at.Kind() == reflect.Interface && t.Implements(at)) || t == at {
+			return a
+		}
+	}
+	return nil
+}
+
+func isScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == typeOfTime || reflect.PtrTo(t).Implements(typeOfTextUnmarshaler) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func isBindable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+type namedArgument struct {
+	name string
+	t    reflect.Type
+}
+
+func (a *namedArgument) Type() reflect.Type {
+	return a.t
+}
+
+func (a *namedArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	s, isVar := c.Vars[a.name]
+	if !isVar {
+		form, err := c.Form()
+		if err != nil {
+			return reflect.ValueOf(nil), err
+		}
+		s = form.Get(a.name)
+	}
+
+	if s == "" {
+		if a.t.Kind() == reflect.Ptr {
+			return reflect.Zero(a.t), nil
+		}
+		if isVar {
+			return reflect.ValueOf(nil), gmvc.NewStatusError(http.StatusNotFound, fmt.Errorf("empty path var '%s'", a.name))
+		}
+		return reflect.ValueOf(nil), gmvc.NewStatusError(http.StatusBadRequest, fmt.Errorf("missing argument '%s'", a.name))
+	}
+
+	t := a.t
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	v := reflect.New(t)
+	if err := gmvc.Parse(s, v.Interface()); err != nil {
+		return reflect.ValueOf(nil), &gmvc.BindError{Field: a.name, Key: a.name, Err: err}
+	}
+	if a.t.Kind() == reflect.Ptr {
+		return v, nil
+	}
+	return v.Elem(), nil
+}
+
+type bindArgument struct {
 	t reflect.Type
 }
 
-func (a *zeroArgument) Type() reflect.Type {
+func (a *bindArgument) Type() reflect.Type {
 	return a.t
 }
 
-func (a *zeroArgument) Get(c *gmvc.Context) (reflect.Value, error) {
-	return reflect.Zero(a.t), nil
+func (a *bindArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	t := a.t
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	v := reflect.New(t)
+	if err := c.Bind(v.Interface()); err != nil {
+		return reflect.ValueOf(nil), err
+	}
+
+	if a.t.Kind() == reflect.Ptr {
+		return v, nil
+	}
+	return v.Elem(), nil
 }
 
 type contextArgument struct {
@@ -96,6 +212,36 @@ func (a *valuesArgument) Get(c *gmvc.Context) (reflect.Value, error) {
 	return reflect.ValueOf(v), nil
 }
 
+type queryValuesArgument struct {
+}
+
+func (a *queryValuesArgument) Type() reflect.Type {
+	return typeOfQueryValues
+}
+
+func (a *queryValuesArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	v, err := c.Query()
+	if err != nil {
+		return reflect.ValueOf(nil), err
+	}
+	return reflect.ValueOf(gmvc.QueryValues{Values: v}), nil
+}
+
+type postFormValuesArgument struct {
+}
+
+func (a *postFormValuesArgument) Type() reflect.Type {
+	return typeOfPostFormValues
+}
+
+func (a *postFormValuesArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	v, err := c.PostForm()
+	if err != nil {
+		return reflect.ValueOf(nil), err
+	}
+	return reflect.ValueOf(gmvc.PostFormValues{Values: v}), nil
+}
+
 type multipartFormArgument struct {
 }
 
@@ -111,6 +257,24 @@ func (a *multipartFormArgument) Get(c *gmvc.Context) (reflect.Value, error) {
 	return reflect.ValueOf(v), nil
 }
 
+type multipartFormValueArgument struct {
+}
+
+func (a *multipartFormValueArgument) Type() reflect.Type {
+	return typeOfMultipartFormValue
+}
+
+func (a *multipartFormValueArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	v, err := c.MultipartForm(0)
+	if err != nil {
+		return reflect.ValueOf(nil), err
+	}
+	if v == nil {
+		return reflect.Zero(typeOfMultipartFormValue), nil
+	}
+	return reflect.ValueOf(v).Elem(), nil
+}
+
 type responseWriterArgument struct {
 }
 
@@ -143,3 +307,44 @@ func (a *writerArgument) Type() reflect.Type {
 func (a *writerArgument) Get(c *gmvc.Context) (reflect.Value, error) {
 	return reflect.ValueOf(c.ResponseWriter), nil
 }
+
+type sessionArgument struct {
+}
+
+func (a *sessionArgument) Type() reflect.Type {
+	return typeOfSession
+}
+
+func (a *sessionArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	s, err := c.Session(true)
+	if err != nil {
+		return reflect.ValueOf(nil), err
+	}
+	return reflect.ValueOf(&s).Elem(), nil
+}
+
+type resolverArgument struct {
+	resolver ArgumentResolver
+	t        reflect.Type
+}
+
+func (a *resolverArgument) Type() reflect.Type {
+	return a.t
+}
+
+func (a *resolverArgument) Get(c *gmvc.Context) (reflect.Value, error) {
+	x, err := a.resolver.Resolve(c, a.t)
+	if err != nil {
+		return reflect.ValueOf(nil), err
+	}
+
+	v := reflect.New(a.t).Elem()
+	if x != nil {
+		xv := reflect.ValueOf(x)
+		if !xv.Type().AssignableTo(a.t) {
+			return reflect.ValueOf(nil), fmt.Errorf("resolver returns %s for argument %s", xv.Type(), a.t)
+		}
+		v.Set(xv)
+	}
+	return v, nil
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/hujh/gmvc"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type argumentForm struct {
+	Name string `form:"name"`
+}
+
+type argumentController struct{}
+
+func (ac *argumentController) Routes() []Route {
+	return []Route{
+		{Methods: "GET", Pattern: "/page", Handler: (*argumentController).Page, Params: []string{"page", "size"}},
+		{Methods: "GET", Pattern: "/day/{day:.*}", Handler: (*argumentController).Day, Params: []string{"day"}},
+		{Methods: "POST", Pattern: "/form", Handler: (*argumentController).Form},
+		{Methods: "POST", Pattern: "/upload", Handler: (*argumentController).Upload},
+		{Methods: "GET", Pattern: "/items/{id}", Handler: (*argumentController).Item, Params: []string{"id"}},
+	}
+}
+
+func (ac *argumentController) Page(w http.ResponseWriter, page int, size *int) {
+	if size == nil {
+		fmt.Fprintf(w, "page %d", page)
+		return
+	}
+	fmt.Fprintf(w, "page %d size %d", page, *size)
+}
+
+func (ac *argumentController) Day(w http.ResponseWriter, day string) {
+	fmt.Fprintf(w, "day %s", day)
+}
+
+func (ac *argumentController) Form(w http.ResponseWriter, form *argumentForm, values gmvc.Values) {
+	fmt.Fprintf(w, "%s %s", form.Name, values.Get("name"))
+}
+
+func (ac *argumentController) Upload(w http.ResponseWriter, form gmvc.MultipartForm, pform *gmvc.MultipartForm) {
+	fmt.Fprintf(w, "%s %s %v", form.Values.Get("name"), form.File("file").Filename, reflect.DeepEqual(form, *pform))
+}
+
+func (ac *argumentController) Item(w http.ResponseWriter, id int64, vars gmvc.PathVars, r *http.Request, c *gmvc.Context) {
+	fmt.Fprintf(w, "item %d %s %v", id, vars.Get("id"), r == c.Request)
+}
+
+func TestArguments(t *testing.T) {
+	app := gmvc.NewApp()
+	if err := RegisterRoutes(app.Router, "/", &argumentController{}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, app, []routeTest{
+		{"GET", "/page?page=2", "", http.StatusOK, "page 2"},
+		{"GET", "/page?page=2&size=10", "", http.StatusOK, "page 2 size 10"},
+		{"GET", "/page", "", http.StatusBadRequest, ""},
+		{"GET", "/page?page=", "", http.StatusBadRequest, ""},
+		{"GET", "/page?page=x", "", http.StatusBadRequest, ""},
+		{"GET", "/day/mon", "", http.StatusOK, "day mon"},
+		{"GET", "/day/", "", http.StatusNotFound, ""},
+		{"POST", "/form", "name=pen", http.StatusOK, "pen pen"},
+		{"GET", "/items/7", "", http.StatusOK, "item 7 7 true"},
+		{"GET", "/items/x", "", http.StatusBadRequest, ""},
+	})
+}
+
+func TestMultipartFormArgument(t *testing.T) {
+	app := gmvc.NewApp()
+	if err := RegisterRoutes(app.Router, "/", &argumentController{}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	mw.WriteField("name", "pen")
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	fw.Write([]byte("hello"))
+	mw.Close()
+
+	r := httptest.NewRequest("POST", "/upload", buf)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK || w.Body.String() != "pen a.txt true" {
+		t.Errorf("status %d, body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestUnresolvableArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		params  []string
+	}{
+		{"no name", func(id int) {}, nil},
+		{"unused name", func(id int) {}, []string{"id", "x"}},
+		{"unknown type", func(ch chan int) {}, nil},
+	}
+
+	for _, tt := range tests {
+		err := RegisterRoutes(gmvc.NewRouter(), "/", routesOf{{Pattern: "/", Handler: tt.handler, Params: tt.params}})
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+type routesOf []Route
+
+func (r routesOf) Routes() []Route {
+	return r
+}
@@ -9,21 +9,70 @@ import (
 )
 
 var (
-	regexMapping  = regexp.MustCompile("^((?:\\S+\\s+){0,1}/\\S*)\\s+(\\S+)$")
-	mappingSyntax = "[HttpMethods] <UrlPattern> <ControllerMethod>"
+	regexMapping  = regexp.MustCompile("^((?:\\S+\\s+){0,1}/\\S*)\\s+(\\w+)(?:\\(([^)]*)\\))?((?:\\s+\\w+)*)(?:\\s+=>\\s*(\\S+))?$")
+	mappingSyntax = "[HttpMethods] <UrlPattern> <ControllerMethod>[(argName, ...)] [FilterMethod ...] [=> ViewName]"
 )
 
 type Controller interface {
 	RequestMapping() string
 }
 
-func Register(router *gmvc.Router, pattern string, controller Controller) error {
+type RouteController interface {
+	Routes() []Route
+}
+
+type Route struct {
+	Methods string
+	Pattern string
+	Handler interface{}
+	Params  []string
+	View    string
+	Name    string
+	Filters []gmvc.Filter
+}
+
+func Register(router *gmvc.Router, pattern string, controller Controller, resolvers ...ArgumentResolver) error {
+	return register(router, pattern, controller, resolvers)
+}
+
+func RegisterRoutes(router *gmvc.Router, pattern string, controller RouteController, resolvers ...ArgumentResolver) error {
+	return register(router, pattern, controller, resolvers)
+}
+
+// RegisterFactory registers a controller created by factory for each
+// request, the controller implements Controller or RouteController.
+func RegisterFactory(router *gmvc.Router, pattern string, factory Factory, resolvers ...ArgumentResolver) error {
+	if factory == nil {
+		return fmt.Errorf("controller factory is nil")
+	}
+	return register(router, pattern, factory, resolvers)
+}
+
+func register(router *gmvc.Router, pattern string, controller interface{}, resolvers []ArgumentResolver) error {
 	router, err := router.Subrouter(pattern)
 	if err != nil {
 		return err
 	}
 
-	t := reflect.TypeOf(controller)
+	resolvers = append(resolvers[:len(resolvers):len(resolvers)], globalResolvers()...)
+
+	b, err := newBinding(controller, resolvers)
+	if err != nil {
+		return err
+	}
+
+	switch c := b.sample.(type) {
+	case RouteController:
+		return registerRoutes(router, b, c.Routes(), resolvers)
+	case Controller:
+		return registerMapping(router, b, c, resolvers)
+	}
+
+	return fmt.Errorf("controller %T implements neither Controller nor RouteController", b.sample)
+}
+
+func registerMapping(router *gmvc.Router, b *binding, controller Controller, resolvers []ArgumentResolver) error {
+	t := b.typ
 	mapping := controller.RequestMapping()
 	for _, line := range strings.Split(mapping, "\n") {
 		line = strings.TrimSpace(line)
@@ -38,25 +87,96 @@ func Register(router *gmvc.Router, pattern string, controller Controller) error
 
 		pattern := match[1]
 		methodName := match[2]
+		names := splitNames(match[3])
 
-		method, ok := t.MethodByName(methodName)
-		if !ok {
-			return fmt.Errorf("controller %s has incorrect mapping: '%s', reason: no method %s", t, line, methodName)
+		filters, err := lookupFilters(b, strings.Fields(match[4]))
+		if err != nil {
+			return fmt.Errorf("controller %s has incorrect mapping: '%s', reason: %v", t, line, err)
 		}
 
-		if strings.Title(method.Name) != method.Name {
-			return fmt.Errorf("controller %s has incorrect mapping: '%s', reason: %s is not accessable", t, line, methodName)
+		method, err := lookupMethod(t, methodName)
+		if err != nil {
+			return fmt.Errorf("controller %s has incorrect mapping: '%s', reason: %v", t, line, err)
 		}
 
-		handler, err := newMethodHandler(controller, method)
+		handler, err := newMethodHandler(b, method, &handlerOptions{names: names, resolvers: resolvers, view: match[5]})
 		if err != nil {
+			return fmt.Errorf("controller %s has incorrect mapping: '%s', reason: %v", t, line, err)
+		}
+
+		h := intercept(b, b.sample, gmvc.WithFilters(handler, filters...))
+		if err := router.Handle(pattern, h); err != nil {
 			return err
 		}
+	}
 
-		if err := router.Handle(pattern, handler); err != nil {
+	return nil
+}
+
+func registerRoutes(router *gmvc.Router, b *binding, routes []Route, resolvers []ArgumentResolver) error {
+	t := b.typ
+
+	for _, r := range routes {
+		pattern := r.Pattern
+		if pattern == "" {
+			pattern = "/"
+		}
+		if r.Methods != "" {
+			pattern = r.Methods + " " + pattern
+		}
+
+		var handler gmvc.Handler
+		var err error
+
+		opts := &handlerOptions{names: r.Params, resolvers: resolvers, view: r.View}
+		switch h := r.Handler.(type) {
+		case string:
+			var method reflect.Method
+			if method, err = lookupMethod(t, h); err == nil {
+				handler, err = newMethodHandler(b, method, opts)
+			}
+		case action:
+			handler, err = h.handler(b)
+		default:
+			handler, err = newFuncHandler(b, r.Handler, opts)
+		}
+		if err != nil {
+			return fmt.Errorf("controller %s has incorrect route: '%s', reason: %v", t, pattern, err)
+		}
+
+		h := intercept(b, b.sample, gmvc.WithFilters(handler, r.Filters...))
+		if r.Name != "" {
+			err = router.HandleNamed(r.Name, pattern, h)
+		} else {
+			err = router.Handle(pattern, h)
+		}
+		if err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+func lookupMethod(t reflect.Type, name string) (reflect.Method, error) {
+	method, ok := t.MethodByName(name)
+	if !ok {
+		return method, fmt.Errorf("no method %s", name)
+	}
+
+	if strings.Title(method.Name) != method.Name {
+		return method, fmt.Errorf("%s is not accessable", name)
+	}
+
+	return method, nil
+}
+
+func splitNames(s string) []string {
+	var names []string
+	for _, n := range strings.Split(s, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
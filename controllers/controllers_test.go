@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hujh/gmvc"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mappingController struct{}
+
+func (mc *mappingController) RequestMapping() string {
+	return `
+	GET   /{id}   Get(id)
+	POST  /       Create    Audit
+	`
+}
+
+func (mc *mappingController) Get(w http.ResponseWriter, id int) {
+	fmt.Fprintf(w, "get %d", id)
+}
+
+func (mc *mappingController) Create(c *gmvc.Context) error {
+	_, err := io.WriteString(c.ResponseWriter, "create by "+c.Attrs["audit"].(string))
+	return err
+}
+
+func (mc *mappingController) Audit(fc *gmvc.FilterContext) error {
+	fc.Context.Attrs["audit"] = "filter"
+	return fc.Next()
+}
+
+type routesController struct {
+	n int
+}
+
+func (rc *routesController) Routes() []Route {
+	return []Route{
+		{Methods: "GET", Pattern: "/count", Handler: "Count"},
+		{Methods: "GET", Pattern: "/action", Handler: Action((*routesController).Count)},
+		{Methods: "GET", Pattern: "/func", Handler: func(w http.ResponseWriter) { io.WriteString(w, "func") }},
+		{Methods: "GET", Pattern: "/{id}", Handler: (*routesController).Get, Params: []string{"id"}, Name: "item"},
+	}
+}
+
+func (rc *routesController) Get(w http.ResponseWriter, id string) {
+	io.WriteString(w, "item "+id)
+}
+
+func (rc *routesController) Count(c *gmvc.Context) error {
+	rc.n++
+	_, err := fmt.Fprintf(c.ResponseWriter, "count %d", rc.n)
+	return err
+}
+
+func serve(app *gmvc.App, method string, target string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+type routeTest struct {
+	method string
+	target string
+	body   string
+	status int
+	want   string
+}
+
+func checkRoutes(t *testing.T, app *gmvc.App, tests []routeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		w := serve(app, tt.method, tt.target, tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, w.Code, tt.status)
+			continue
+		}
+		if tt.want != "" && strings.TrimSpace(w.Body.String()) != tt.want {
+			t.Errorf("%s %s: body = %q, want %q", tt.method, tt.target, w.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	app := gmvc.NewApp()
+	if err := Register(app.Router, "/m", &mappingController{}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, app, []routeTest{
+		{"GET", "/m/7", "", http.StatusOK, "get 7"},
+		{"POST", "/m/", "", http.StatusOK, "create by filter"},
+		{"DELETE", "/m/7", "", http.StatusMethodNotAllowed, ""},
+	})
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	app := gmvc.NewApp()
+	if err := RegisterRoutes(app.Router, "/r", &routesController{}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, app, []routeTest{
+		{"GET", "/r/count", "", http.StatusOK, "count 1"},
+		{"GET", "/r/action", "", http.StatusOK, "count 2"},
+		{"GET", "/r/func", "", http.StatusOK, "func"},
+		{"GET", "/r/abc", "", http.StatusOK, "item abc"},
+	})
+
+	if u, err := app.Router.URL("item", gmvc.PathVars{"id": "5"}); err != nil || u != "/r/5" {
+		t.Errorf("URL = %q, %v, want /r/5", u, err)
+	}
+}
+
+func TestRegisterFactory(t *testing.T) {
+	app := gmvc.NewApp()
+	if err := RegisterFactory(app.Router, "/f", PerRequest(&routesController{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterFactory(app.Router, "/g", func() interface{} { return &mappingController{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, app, []routeTest{
+		{"GET", "/f/count", "", http.StatusOK, "count 1"},
+		{"GET", "/f/count", "", http.StatusOK, "count 1"},
+		{"GET", "/g/3", "", http.StatusOK, "get 3"},
+	})
+}
+
+type badMappingController struct {
+	mapping string
+}
+
+func (bc *badMappingController) RequestMapping() string {
+	return bc.mapping
+}
+
+func (bc *badMappingController) Get(c *gmvc.Context) error {
+	return nil
+}
+
+func TestRegisterErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		reg  func(rt *gmvc.Router) error
+	}{
+		{"bad syntax", func(rt *gmvc.Router) error {
+			return Register(rt, "/", &badMappingController{"GET"})
+		}},
+		{"unknown method", func(rt *gmvc.Router) error {
+			return Register(rt, "/", &badMappingController{"GET / Missing"})
+		}},
+		{"unknown filter", func(rt *gmvc.Router) error {
+			return Register(rt, "/", &badMappingController{"GET / Get Missing"})
+		}},
+		{"nil factory", func(rt *gmvc.Router) error {
+			return RegisterFactory(rt, "/", nil)
+		}},
+		{"bad prototype", func(rt *gmvc.Router) error {
+			return RegisterFactory(rt, "/", PerRequest(mappingController{}))
+		}},
+		{"factory without mapping", func(rt *gmvc.Router) error {
+			return RegisterFactory(rt, "/", func() interface{} { return &struct{}{} })
+		}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.reg(gmvc.NewRouter()); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hujh/gmvc"
+	"reflect"
+)
+
+const (
+	instanceAttr = "gmvc/controllers.instance"
+)
+
+type Factory func() interface{}
+
+type invalidPrototype struct {
+	err error
+}
+
+func PerRequest(prototype interface{}) Factory {
+	t := reflect.TypeOf(prototype)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		err := fmt.Errorf("PerRequest requires a pointer to struct, got %v", t)
+		return func() interface{} {
+			return &invalidPrototype{err}
+		}
+	}
+
+	t = t.Elem()
+	return func() interface{} {
+		return reflect.New(t).Interface()
+	}
+}
+
+type binding struct {
+	sample     interface{}
+	shared     reflect.Value
+	factory    Factory
+	typ        reflect.Type
+	injections []*injection
+}
+
+func newBinding(controller interface{}, resolvers []ArgumentResolver) (*binding, error) {
+	f, ok := controller.(Factory)
+	if !ok {
+		if fn, isFunc := controller.(func() interface{}); isFunc {
+			f, ok = Factory(fn), true
+		}
+	}
+
+	if !ok {
+		return &binding{
+			sample: controller,
+			shared: reflect.ValueOf(controller),
+			typ:    reflect.TypeOf(controller),
+		}, nil
+	}
+
+	sample := f()
+	if sample == nil {
+		return nil, fmt.Errorf("controller factory returns nil")
+	}
+	if ip, ok := sample.(*invalidPrototype); ok {
+		return nil, ip.err
+	}
+
+	b := &binding{
+		sample:  sample,
+		factory: f,
+		typ:     reflect.TypeOf(sample),
+	}
+
+	injections, err := newInjections(b.typ, resolvers)
+	if err != nil {
+		return nil, fmt.Errorf("controller %s: %v", b.typ, err)
+	}
+	b.injections = injections
+
+	return b, nil
+}
+
+func (b *binding) instance(c *gmvc.Context) (reflect.Value, error) {
+	if b.factory == nil {
+		return b.shared, nil
+	}
+
+	instances, _ := c.Attrs[instanceAttr].(map[reflect.Type]reflect.Value)
+	if v, ok := instances[b.typ]; ok {
+		return v, nil
+	}
+
+	v := reflect.ValueOf(b.factory())
+	if v.Type() != b.typ {
+		return reflect.Value{}, fmt.Errorf("controller factory returns %s, want %s", v.Type(), b.typ)
+	}
+
+	for _, inj := range b.injections {
+		if err := inj.inject(c, v); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	if instances == nil {
+		instances = make(map[reflect.Type]reflect.Value)
+		c.Attrs[instanceAttr] = instances
+	}
+	instances[b.typ] = v
+	return v, nil
+}
+
+type injection struct {
+	index []int
+	name  string
+	t     reflect.Type
+	arg   argument
+}
+
+func newInjections(t reflect.Type, resolvers []ArgumentResolver) ([]*injection, error) {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var injections []*injection
+	st := t.Elem()
+
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		name, ok := sf.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("field %s is not exported", sf.Name)
+		}
+
+		inj := &injection{index: sf.Index, name: name, t: sf.Type}
+		if name == "" {
+			inj.arg = resolveArgument(sf.Type, resolvers)
+			if inj.arg == nil {
+				return nil, fmt.Errorf("unresolvable field %s (%s)", sf.Name, sf.Type)
+			}
+		}
+		injections = append(injections, inj)
+	}
+
+	return injections, nil
+}
+
+func (inj *injection) inject(c *gmvc.Context, controller reflect.Value) error {
+	var v reflect.Value
+
+	if inj.arg != nil {
+		av, err := inj.arg.Get(c)
+		if err != nil {
+			return err
+		}
+		v = av
+	} else {
+		x := c.App().Attrs.Get(inj.name)
+		if x == nil {
+			return fmt.Errorf("no app attribute '%s' to inject", inj.name)
+		}
+		v = reflect.ValueOf(x)
+		if !v.Type().AssignableTo(inj.t) {
+			return fmt.Errorf("app attribute '%s' is %s, not assignable to %s", inj.name, v.Type(), inj.t)
+		}
+	}
+
+	controller.Elem().FieldByIndex(inj.index).Set(v)
+	return nil
+}
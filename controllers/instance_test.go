@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hujh/gmvc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type counter struct {
+	n int
+}
+
+type instanceController struct {
+	Counter *counter      `inject:"counter"`
+	Context *gmvc.Context `inject:""`
+	calls   int
+}
+
+func (ic *instanceController) RequestMapping() string {
+	return `
+	GET  /   Get   Count
+	`
+}
+
+func (ic *instanceController) Count(fc *gmvc.FilterContext) error {
+	ic.calls++
+	return fc.Next()
+}
+
+func (ic *instanceController) Get(w http.ResponseWriter, c *gmvc.Context) {
+	ic.Counter.n++
+	fmt.Fprintf(w, "calls %d, same context %v", ic.calls+1, ic.Context == c)
+}
+
+type otherController struct {
+	calls int
+}
+
+func TestPerRequest(t *testing.T) {
+	app := gmvc.NewApp()
+	c := &counter{}
+	app.Attrs.Set("counter", c)
+	if err := RegisterFactory(app.Router, "/", PerRequest(&instanceController{})); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, app, []routeTest{
+		{"GET", "/", "", http.StatusOK, "calls 2, same context true"},
+		{"GET", "/", "", http.StatusOK, "calls 2, same context true"},
+	})
+	if c.n != 2 {
+		t.Errorf("counter = %d, want 2", c.n)
+	}
+}
+
+func TestInjectionErrors(t *testing.T) {
+	app := gmvc.NewApp()
+	app.Attrs.Set("counter", "not a counter")
+	if err := RegisterFactory(app.Router, "/", PerRequest(&instanceController{})); err != nil {
+		t.Fatal(err)
+	}
+	checkRoutes(t, app, []routeTest{{"GET", "/", "", http.StatusInternalServerError, ""}})
+
+	type unexported struct {
+		db *counter `inject:"db"`
+	}
+	type unresolvable struct {
+		Ch chan int `inject:""`
+	}
+	for _, prototype := range []interface{}{&unexported{}, &unresolvable{}} {
+		if _, err := newBinding(PerRequest(prototype), nil); err == nil {
+			t.Errorf("%T: no error", prototype)
+		}
+	}
+}
+
+func TestInstanceCache(t *testing.T) {
+	b1, err := newBinding(PerRequest(&instanceController{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := newBinding(PerRequest(&otherController{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b1.injections = nil
+
+	c := &gmvc.Context{Request: httptest.NewRequest("GET", "/", nil), Attrs: make(gmvc.Attrs)}
+	v1, _ := b1.instance(c)
+	v2, _ := b2.instance(c)
+	again1, _ := b1.instance(c)
+	again2, _ := b2.instance(c)
+
+	if v1.Interface() != again1.Interface() || v2.Interface() != again2.Interface() {
+		t.Error("nested controllers of a request overwrite each other")
+	}
+
+	other := &gmvc.Context{Request: c.Request, Attrs: make(gmvc.Attrs)}
+	if v, _ := b1.instance(other); v.Interface() == v1.Interface() {
+		t.Error("requests share a per-request controller")
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hujh/gmvc"
+	"reflect"
+)
+
+var (
+	typeOfFilterFunc = reflect.TypeOf(gmvc.FilterFunc(nil))
+)
+
+// ErrHandled is returned by Before when it has written the response, like
+// a filter which does not call Next, the method and After are skipped.
+var ErrHandled = errors.New("request handled by interceptor")
+
+type BeforeInterceptor interface {
+	Before(c *gmvc.Context) error
+}
+
+type AfterInterceptor interface {
+	After(c *gmvc.Context, err error) error
+}
+
+type interceptHandler struct {
+	binding *binding
+	before  bool
+	after   bool
+	handler gmvc.Handler
+}
+
+func intercept(b *binding, controller interface{}, handler gmvc.Handler) gmvc.Handler {
+	_, before := controller.(BeforeInterceptor)
+	_, after := controller.(AfterInterceptor)
+	if !before && !after {
+		return handler
+	}
+
+	return &interceptHandler{
+		binding: b,
+		before:  before,
+		after:   after,
+		handler: handler,
+	}
+}
+
+func (h *interceptHandler) HandleRequest(c *gmvc.Context) error {
+	v, err := h.binding.instance(c)
+	if err != nil {
+		return err
+	}
+	controller := v.Interface()
+
+	if h.before {
+		err = controller.(BeforeInterceptor).Before(c)
+		if errors.Is(err, ErrHandled) {
+			return nil
+		}
+	}
+
+	if err == nil {
+		err = h.handler.HandleRequest(c)
+	}
+
+	if h.after {
+		err = controller.(AfterInterceptor).After(c, err)
+	}
+
+	return err
+}
+
+func lookupFilters(b *binding, names []string) ([]gmvc.Filter, error) {
+	filters := make([]gmvc.Filter, len(names))
+
+	for i, name := range names {
+		method, err := lookupMethod(b.typ, name)
+		if err != nil {
+			return nil, err
+		}
+
+		mt := method.Type
+		if mt.NumIn() != 2 || mt.NumOut() != 1 || mt.In(1) != typeOfFilterFunc.In(0) || mt.Out(0) != typeOfFilterFunc.Out(0) {
+			return nil, fmt.Errorf("%s is not a filter method", name)
+		}
+
+		if b.factory == nil {
+			filters[i] = gmvc.FilterFunc(b.shared.Method(method.Index).Interface().(func(*gmvc.FilterContext) error))
+			continue
+		}
+
+		fn := method.Func
+		filters[i] = gmvc.FilterFunc(func(fc *gmvc.FilterContext) error {
+			v, err := b.instance(fc.Context)
+			if err != nil {
+				return err
+			}
+			out := fn.Call([]reflect.Value{v, reflect.ValueOf(fc)})
+			if err, _ := out[0].Interface().(error); err != nil {
+				return err
+			}
+			return nil
+		})
+	}
+
+	return filters, nil
+}
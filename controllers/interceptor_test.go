@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/hujh/gmvc"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type interceptController struct {
+	calls []string
+}
+
+func (ic *interceptController) RequestMapping() string {
+	return `
+	GET  /ok        Ok
+	GET  /fail      Fail
+	GET  /login     Ok
+	GET  /denied    Ok
+	GET  /audit     Ok      Audit
+	`
+}
+
+func (ic *interceptController) Before(c *gmvc.Context) error {
+	ic.calls = append(ic.calls, "before")
+	switch c.Request.URL.Path {
+	case "/login":
+		c.Redirect("/signin", http.StatusFound)
+		return ErrHandled
+	case "/denied":
+		return gmvc.NewStatusError(http.StatusForbidden, errors.New("denied"))
+	}
+	return nil
+}
+
+func (ic *interceptController) After(c *gmvc.Context, err error) error {
+	ic.calls = append(ic.calls, "after")
+	if err != nil && err.Error() == "fail" {
+		return gmvc.NewStatusError(http.StatusConflict, err)
+	}
+	return err
+}
+
+func (ic *interceptController) Ok(w http.ResponseWriter) {
+	ic.calls = append(ic.calls, "method")
+	io.WriteString(w, "ok")
+}
+
+func (ic *interceptController) Fail() error {
+	ic.calls = append(ic.calls, "method")
+	return errors.New("fail")
+}
+
+func (ic *interceptController) Audit(fc *gmvc.FilterContext) error {
+	ic.calls = append(ic.calls, "filter")
+	return fc.Next()
+}
+
+func TestInterceptors(t *testing.T) {
+	tests := []struct {
+		target string
+		status int
+		calls  string
+	}{
+		{"/ok", http.StatusOK, "before method after"},
+		{"/fail", http.StatusConflict, "before method after"},
+		{"/login", http.StatusFound, "before"},
+		{"/denied", http.StatusForbidden, "before after"},
+		{"/audit", http.StatusOK, "before filter method after"},
+	}
+
+	for _, tt := range tests {
+		ic := &interceptController{}
+		app := gmvc.NewApp()
+		if err := Register(app.Router, "/", ic); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.target, w.Code, tt.status)
+		}
+		if calls := strings.Join(ic.calls, " "); calls != tt.calls {
+			t.Errorf("%s: calls = %q, want %q", tt.target, calls, tt.calls)
+		}
+	}
+}
+
+func TestBadFilterMethod(t *testing.T) {
+	err := Register(gmvc.NewRouter(), "/", &badMappingController{"GET / Get Get"})
+	if err == nil {
+		t.Error("Register accepts a non filter method as filter")
+	}
+}
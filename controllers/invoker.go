@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hujh/gmvc"
+	"net/http"
+	"reflect"
+)
+
+type invoker func(c *gmvc.Context) error
+
+func newInvoker(fn reflect.Value, args []argument, outs []int) invoker {
+	if len(outs) > 0 {
+		return nil
+	}
+	for _, arg := range args {
+		if !isBuiltin(arg) {
+			return nil
+		}
+	}
+
+	switch f := fn.Interface().(type) {
+	case func() error:
+		return func(c *gmvc.Context) error {
+			return f()
+		}
+
+	case func():
+		return func(c *gmvc.Context) error {
+			f()
+			return nil
+		}
+
+	case func(*gmvc.Context) error:
+		return f
+
+	case func(*gmvc.Context):
+		return func(c *gmvc.Context) error {
+			f(c)
+			return nil
+		}
+
+	case func(*gmvc.Context, gmvc.PathVars) error:
+		return func(c *gmvc.Context) error {
+			return f(c, c.Vars)
+		}
+
+	case func(http.ResponseWriter, *http.Request) error:
+		return func(c *gmvc.Context) error {
+			return f(c.ResponseWriter, c.Request)
+		}
+
+	case func(http.ResponseWriter, *http.Request):
+		return func(c *gmvc.Context) error {
+			f(c.ResponseWriter, c.Request)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func isBuiltin(arg argument) bool {
+	switch arg.(type) {
+	case *contextArgument, *pathVarsArgument, *requestArgument, *responseWriterArgument:
+		return true
+	}
+	return false
+}
+
+type action interface {
+	handler(b *binding) (gmvc.Handler, error)
+}
+
+type actionFunc[C any] func(C, *gmvc.Context) error
+
+func Action[C any](f func(C, *gmvc.Context) error) interface{} {
+	return actionFunc[C](f)
+}
+
+func (f actionFunc[C]) handler(b *binding) (gmvc.Handler, error) {
+	if t := reflect.TypeOf((*C)(nil)).Elem(); t != b.typ {
+		return nil, fmt.Errorf("action receiver %s mismatches controller %s", t, b.typ)
+	}
+
+	if b.factory == nil {
+		controller := b.shared.Interface().(C)
+		return gmvc.HandlerFunc(func(c *gmvc.Context) error {
+			return f(controller, c)
+		}), nil
+	}
+
+	return gmvc.HandlerFunc(func(c *gmvc.Context) error {
+		v, err := b.instance(c)
+		if err != nil {
+			return err
+		}
+		return f(v.Interface().(C), c)
+	}), nil
+}
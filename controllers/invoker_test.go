@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"github.com/hujh/gmvc"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type benchController struct {
+	n int
+}
+
+func (bc *benchController) Hello(c *gmvc.Context) error {
+	bc.n++
+	return nil
+}
+
+func (bc *benchController) Serve(w http.ResponseWriter, r *http.Request) {
+	bc.n++
+}
+
+func (bc *benchController) Bind(id int) error {
+	return nil
+}
+
+func (bc *benchController) Result(c *gmvc.Context) (string, error) {
+	return "", nil
+}
+
+func benchHandler(b *testing.B, name string, invoke bool) *methodHandler {
+	bc := &benchController{}
+	binding, err := newBinding(bc, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	method, ok := reflect.TypeOf(bc).MethodByName(name)
+	if !ok {
+		b.Fatalf("no method %s", name)
+	}
+
+	h, err := newMethodHandler(binding, method, &handlerOptions{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if invoke && h.invoke == nil {
+		b.Fatalf("%s has no invoker", name)
+	}
+	if !invoke {
+		h.invoke = nil
+	}
+	return h
+}
+
+func benchContext() *gmvc.Context {
+	return &gmvc.Context{
+		ResponseWriter: httptest.NewRecorder(),
+		Request:        httptest.NewRequest("GET", "/", nil),
+	}
+}
+
+func runHandler(b *testing.B, h gmvc.Handler) {
+	c := benchContext()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := h.HandleRequest(c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInvokerContext(b *testing.B) {
+	runHandler(b, benchHandler(b, "Hello", true))
+}
+
+func BenchmarkReflectContext(b *testing.B) {
+	runHandler(b, benchHandler(b, "Hello", false))
+}
+
+func BenchmarkInvokerHTTP(b *testing.B) {
+	runHandler(b, benchHandler(b, "Serve", true))
+}
+
+func BenchmarkReflectHTTP(b *testing.B) {
+	runHandler(b, benchHandler(b, "Serve", false))
+}
+
+func BenchmarkAction(b *testing.B) {
+	binding, err := newBinding(&benchController{}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	h, err := Action((*benchController).Hello).(action).handler(binding)
+	if err != nil {
+		b.Fatal(err)
+	}
+	runHandler(b, h)
+}
+
+func TestInvoker(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		names  []string
+		invoke bool
+	}{
+		{"context", "Hello", nil, true},
+		{"http", "Serve", nil, true},
+		{"named argument", "Bind", []string{"id"}, false},
+		{"result", "Result", nil, false},
+	}
+
+	bc := &benchController{}
+	binding, err := newBinding(bc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		method, _ := reflect.TypeOf(bc).MethodByName(tt.method)
+		h, err := newMethodHandler(binding, method, &handlerOptions{names: tt.names})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if (h.invoke != nil) != tt.invoke {
+			t.Errorf("%s: has invoker %v, want %v", tt.name, h.invoke != nil, tt.invoke)
+		}
+	}
+}
+
+func TestAction(t *testing.T) {
+	app := gmvc.NewApp()
+	routes := routesOf{{Pattern: "/", Handler: Action((*benchController).Hello)}}
+	if err := RegisterRoutes(app.Router, "/", routes); err == nil {
+		t.Error("Action with a mismatched receiver is registered")
+	}
+
+	bc := &benchController{}
+	binding, _ := newBinding(bc, nil)
+	h, err := Action((*benchController).Hello).(action).handler(binding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.HandleRequest(benchContext())
+	h.HandleRequest(benchContext())
+	if bc.n != 2 {
+		t.Errorf("calls = %d, want 2", bc.n)
+	}
+}
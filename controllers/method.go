@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"fmt"
 	"github.com/hujh/gmvc"
 	"net/http"
 	"reflect"
@@ -10,69 +11,158 @@ var (
 	typeOfError = reflect.TypeOf(new(error)).Elem()
 )
 
+type handlerOptions struct {
+	names     []string
+	resolvers []ArgumentResolver
+	view      string
+}
+
 type methodHandler struct {
-	controller reflect.Value
-	method     reflect.Method
-	args       []argument
-	outErr     int
+	binding *binding
+	fn      reflect.Value
+	args    []argument
+	outErr  int
+	outs    []int
+	view    string
+	invoke  invoker
 }
 
-func newMethodHandler(controller interface{}, method reflect.Method) (*methodHandler, error) {
-	numIn := method.Type.NumIn()
-	args := make([]argument, numIn-1)
+func newMethodHandler(b *binding, method reflect.Method, opts *handlerOptions) (*methodHandler, error) {
+	h, err := newHandler(b, method.Func, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.factory == nil {
+		h.invoke = newInvoker(b.shared.Method(method.Index), h.args, h.outs)
+	}
+	return h, nil
+}
+
+func newFuncHandler(b *binding, f interface{}, opts *handlerOptions) (*methodHandler, error) {
+	fn := reflect.ValueOf(f)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, fmt.Errorf("handler %T is not a func", f)
+	}
+
+	ft := fn.Type()
+	if ft.NumIn() > 0 && ft.In(0) == b.typ {
+		return newHandler(b, fn, opts)
+	}
+
+	h, err := newHandler(nil, fn, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	h.invoke = newInvoker(fn, h.args, h.outs)
+	return h, nil
+}
+
+func newHandler(b *binding, fn reflect.Value, opts *handlerOptions) (*methodHandler, error) {
+	ft := fn.Type()
+	numIn := ft.NumIn()
+	names := opts.names
+
+	offset := 0
+	if b != nil {
+		offset = 1
+	}
+	args := make([]argument, numIn-offset)
 
-	for i := 1; i < numIn; i++ {
-		var arg argument
-		t := method.Type.In(i)
+	for i := offset; i < numIn; i++ {
+		t := ft.In(i)
+		arg := resolveArgument(t, opts.resolvers)
 
-		for _, a := range arguments {
-			at := a.Type()
-			if (at.Kind() == reflect.Interface && t.Implements(at)) || t == at {
-				arg = a
-				break
+		switch {
+		case arg != nil:
+		case isScalar(t):
+			if len(names) == 0 {
+				return nil, fmt.Errorf("no name for argument %d (%s) of %s", i-offset, t, ft)
 			}
+			arg = &namedArgument{name: names[0], t: t}
+			names = names[1:]
+		case isBindable(t):
+			arg = &bindArgument{t}
+		default:
+			return nil, fmt.Errorf("unresolvable argument %d (%s) of %s", i-offset, t, ft)
 		}
 
-		if arg == nil {
-			arg = &zeroArgument{t}
-		}
+		args[i-offset] = arg
+	}
 
-		args[i-1] = arg
+	if len(names) > 0 {
+		return nil, fmt.Errorf("unused argument names %v for %s", names, ft)
 	}
 
-	numOut := method.Type.NumOut()
+	numOut := ft.NumOut()
 	outErr := -1
 
 	for i := numOut - 1; i >= 0; i-- {
-		if method.Type.Out(i).Implements(typeOfError) {
+		if ft.Out(i).Implements(typeOfError) {
 			outErr = i
 			break
 		}
 	}
 
+	var outs []int
+	for i := 0; i < numOut; i++ {
+		if i != outErr {
+			outs = append(outs, i)
+		}
+	}
+
+	switch {
+	case len(outs) > 2:
+		return nil, fmt.Errorf("too many results of %s", ft)
+	case len(outs) == 2 && ft.Out(outs[0]).Kind() != reflect.String:
+		return nil, fmt.Errorf("first result of %s must be a view name", ft)
+	}
+
 	return &methodHandler{
-		controller: reflect.ValueOf(controller),
-		method:     method,
-		args:       args,
-		outErr:     outErr,
+		binding: b,
+		fn:      fn,
+		args:    args,
+		outErr:  outErr,
+		outs:    outs,
+		view:    opts.view,
 	}, nil
 }
 
 func (h *methodHandler) HandleRequest(c *gmvc.Context) error {
-	in := make([]reflect.Value, len(h.args)+1)
+	if h.invoke != nil {
+		return h.invoke(c)
+	}
 
-	in[0] = h.controller
+	offset := 0
+	if h.binding != nil {
+		offset = 1
+	}
+
+	in := make([]reflect.Value, len(h.args)+offset)
+
+	if offset == 1 {
+		v, err := h.binding.instance(c)
+		if err != nil {
+			return err
+		}
+		in[0] = v
+	}
 
 	for i, arg := range h.args {
 		v, err := arg.Get(c)
 		if err != nil {
-			c.ErrorStatus(err, http.StatusBadRequest)
+			status := http.StatusBadRequest
+			if se, ok := err.(gmvc.StatusError); ok {
+				status = se.Status()
+			}
+			c.ErrorStatus(err, status)
 			return nil
 		}
-		in[i+1] = v
+		in[i+offset] = v
 	}
 
-	out := h.method.Func.Call(in)
+	out := h.fn.Call(in)
 
 	if h.outErr != -1 {
 		errv := out[h.outErr]
@@ -81,5 +171,12 @@ func (h *methodHandler) HandleRequest(c *gmvc.Context) error {
 		}
 	}
 
+	switch len(h.outs) {
+	case 1:
+		return writeResult(c, out[h.outs[0]].Interface(), h.view)
+	case 2:
+		return render(c, out[h.outs[0]].String(), out[h.outs[1]].Interface(), 0)
+	}
+
 	return nil
 }
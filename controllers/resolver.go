@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"github.com/hujh/gmvc"
+	"reflect"
+	"sync"
+)
+
+var (
+	resolversMutex sync.RWMutex
+	resolvers      []ArgumentResolver
+)
+
+type ArgumentResolver interface {
+	Supports(t reflect.Type) bool
+	Resolve(c *gmvc.Context, t reflect.Type) (interface{}, error)
+}
+
+func RegisterResolver(resolver ArgumentResolver) {
+	resolversMutex.Lock()
+	defer resolversMutex.Unlock()
+	resolvers = append(resolvers, resolver)
+}
+
+func globalResolvers() []ArgumentResolver {
+	resolversMutex.RLock()
+	defer resolversMutex.RUnlock()
+	return append([]ArgumentResolver(nil), resolvers...)
+}
+
+type typeResolver struct {
+	t  reflect.Type
+	fn func(c *gmvc.Context) (interface{}, error)
+}
+
+func TypeResolver(t reflect.Type, fn func(c *gmvc.Context) (interface{}, error)) ArgumentResolver {
+	return &typeResolver{t: t, fn: fn}
+}
+
+func (r *typeResolver) Supports(t reflect.Type) bool {
+	return t == r.t
+}
+
+func (r *typeResolver) Resolve(c *gmvc.Context, t reflect.Type) (interface{}, error) {
+	return r.fn(c)
+}
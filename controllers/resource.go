@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hujh/gmvc"
+	"reflect"
+	"regexp"
+)
+
+var (
+	regexPathVar = regexp.MustCompile("{([^}:]+)(?::[^}]*)?}")
+)
+
+var (
+	resourceActions = []struct {
+		method     string
+		methods    string
+		pattern    string
+		collection bool
+	}{
+		{"Index", "GET", "/", true},
+		{"New", "GET", "/new", true},
+		{"Create", "POST", "/", true},
+		{"Show", "GET", "/{id}", false},
+		{"Edit", "GET", "/{id}/edit", false},
+		{"Update", "PUT,PATCH", "/{id}", false},
+		{"Delete", "DELETE", "/{id}", false},
+	}
+)
+
+type ResourceOptions struct {
+	MethodOverride bool // accept the _method form field on the resource routes
+	Resolvers      []ArgumentResolver
+}
+
+func Resource(router *gmvc.Router, pattern string, controller interface{}, resolvers ...ArgumentResolver) error {
+	return ResourceWithOptions(router, pattern, controller, &ResourceOptions{Resolvers: resolvers})
+}
+
+func ResourceWithOptions(router *gmvc.Router, pattern string, controller interface{}, options *ResourceOptions) error {
+	var opts ResourceOptions
+	if options != nil {
+		opts = *options
+	}
+
+	router, err := router.Subrouter(pattern)
+	if err != nil {
+		return err
+	}
+
+	if opts.MethodOverride {
+		if err := router.Filter("", gmvc.MethodOverride()); err != nil {
+			return err
+		}
+	}
+
+	resolvers := append(opts.Resolvers[:len(opts.Resolvers):len(opts.Resolvers)], globalResolvers()...)
+
+	b, err := newBinding(controller, resolvers)
+	if err != nil {
+		return err
+	}
+
+	var vars []string
+	for _, m := range regexPathVar.FindAllStringSubmatch(pattern, -1) {
+		vars = append(vars, m[1])
+	}
+
+	var routes []Route
+	for _, a := range resourceActions {
+		method, ok := b.typ.MethodByName(a.method)
+		if !ok {
+			continue
+		}
+
+		names := vars
+		if !a.collection {
+			names = append(vars[:len(vars):len(vars)], "id")
+		}
+		if n := countNamed(method.Type, resolvers); n < len(names) {
+			names = names[len(names)-n:]
+		}
+
+		routes = append(routes, Route{
+			Methods: a.methods,
+			Pattern: a.pattern,
+			Handler: a.method,
+			Params:  names,
+		})
+	}
+
+	if len(routes) == 0 {
+		return fmt.Errorf("controller %s has no resource methods", b.typ)
+	}
+
+	return registerRoutes(router, b, routes, resolvers)
+}
+
+func countNamed(ft reflect.Type, resolvers []ArgumentResolver) int {
+	n := 0
+	for i := 1; i < ft.NumIn(); i++ {
+		t := ft.In(i)
+		if resolveArgument(t, resolvers) == nil && isScalar(t) {
+			n++
+		}
+	}
+	return n
+}
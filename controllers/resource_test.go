@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hujh/gmvc"
+	"net/http"
+	"testing"
+)
+
+type photoController struct{}
+
+func (pc *photoController) Index(userId int64) (string, error) {
+	return fmt.Sprintf("index %d", userId), nil
+}
+
+func (pc *photoController) New() (string, error) {
+	return "new", nil
+}
+
+func (pc *photoController) Create(userId int64) (string, error) {
+	return fmt.Sprintf("create %d", userId), nil
+}
+
+func (pc *photoController) Show(userId, id int64) (string, error) {
+	return fmt.Sprintf("show %d %d", userId, id), nil
+}
+
+func (pc *photoController) Update(id int64) (string, error) {
+	return fmt.Sprintf("update %d", id), nil
+}
+
+func (pc *photoController) Delete(id int64) (string, error) {
+	return fmt.Sprintf("delete %d", id), nil
+}
+
+func TestResource(t *testing.T) {
+	app := gmvc.NewApp()
+	if err := Resource(app.Router, "/users/{userId}/photos", &photoController{}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, app, []routeTest{
+		{"GET", "/users/1/photos", "", http.StatusOK, "index 1"},
+		{"GET", "/users/1/photos/new", "", http.StatusOK, "new"},
+		{"POST", "/users/1/photos", "", http.StatusOK, "create 1"},
+		{"GET", "/users/1/photos/2", "", http.StatusOK, "show 1 2"},
+		{"PUT", "/users/1/photos/2", "", http.StatusOK, "update 2"},
+		{"PATCH", "/users/1/photos/2", "", http.StatusOK, "update 2"},
+		{"DELETE", "/users/1/photos/2", "", http.StatusOK, "delete 2"},
+		{"GET", "/users/1/photos/2/edit", "", http.StatusNotFound, ""},
+		{"POST", "/users/1/photos/2", "_method=DELETE", http.StatusMethodNotAllowed, ""},
+	})
+}
+
+func TestResourceMethodOverride(t *testing.T) {
+	app := gmvc.NewApp()
+	opts := &ResourceOptions{MethodOverride: true}
+	if err := ResourceWithOptions(app.Router, "/users/{userId}/photos", &photoController{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	app.Router.HandleFunc("/other/{id}", func(c *gmvc.Context) error {
+		return c.WriteString(c.Request.Method)
+	})
+
+	checkRoutes(t, app, []routeTest{
+		{"POST", "/users/1/photos/2", "_method=DELETE", http.StatusOK, "delete 2"},
+		{"POST", "/users/1/photos/2", "_method=put", http.StatusOK, "update 2"},
+		{"POST", "/users/1/photos", "", http.StatusOK, "create 1"},
+		{"POST", "/other/2", "_method=DELETE", http.StatusOK, "POST"},
+	})
+}
+
+func TestResourceErrors(t *testing.T) {
+	if err := Resource(gmvc.NewRouter(), "/x", &struct{}{}); err == nil {
+		t.Error("Resource accepts a controller without resource methods")
+	}
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/hujh/gmvc"
+	"io"
+	"net/http"
+)
+
+type ModelAndView struct {
+	View   string
+	Model  interface{}
+	Status int
+}
+
+type Redirect struct {
+	URL    string
+	Status int
+}
+
+func writeResult(c *gmvc.Context, result interface{}, view string) error {
+	switch r := result.(type) {
+	case nil:
+		return nil
+
+	case ModelAndView:
+		return render(c, r.View, r.Model, r.Status)
+
+	case *ModelAndView:
+		if r == nil {
+			return nil
+		}
+		return render(c, r.View, r.Model, r.Status)
+
+	case Redirect:
+		return redirect(c, &r)
+
+	case *Redirect:
+		if r == nil {
+			return nil
+		}
+		return redirect(c, r)
+
+	case []byte:
+		_, err := c.ResponseWriter.Write(r)
+		return err
+
+	case string:
+		return c.WriteString(r)
+	}
+
+	if view != "" {
+		return c.Render(view, result)
+	}
+
+	return writeJSON(c, result)
+}
+
+func render(c *gmvc.Context, view string, data interface{}, status int) error {
+	if status == 0 || status == http.StatusOK {
+		return c.Render(view, data)
+	}
+
+	w := c.ResponseWriter
+	c.ResponseWriter = &statusWriter{ResponseWriter: w, status: status}
+	defer func() {
+		c.ResponseWriter = w
+	}()
+
+	return c.Render(view, data)
+}
+
+func redirect(c *gmvc.Context, r *Redirect) error {
+	status := r.Status
+	if status == 0 {
+		status = http.StatusFound
+	}
+	return c.Redirect(r.URL, status)
+}
+
+func writeJSON(c *gmvc.Context, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	h := c.ResponseWriter.Header()
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "application/json")
+	}
+
+	_, err = c.ResponseWriter.Write(b)
+	return err
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wrote {
+		w.wrote = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(w.status)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *statusWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeader(w.status)
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(struct{ io.Writer }{w.ResponseWriter}, r)
+}
+
+func (w *statusWriter) Flush() {
+	w.WriteHeader(w.status)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hujh/gmvc"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testView struct{}
+
+func (v *testView) Render(c *gmvc.Context, name string, data interface{}) error {
+	_, err := fmt.Fprintf(c.ResponseWriter, "%s: %v", name, data)
+	return err
+}
+
+type resultItem struct {
+	Name string `json:"name"`
+}
+
+type resultController struct{}
+
+func (rc *resultController) RequestMapping() string {
+	return `
+	GET  /view       View
+	GET  /mav        MAV
+	GET  /mavptr     MAVPtr
+	GET  /status     Status
+	GET  /redirect   Redirect
+	GET  /see        SeeOther
+	GET  /bytes      Bytes
+	GET  /string     String
+	GET  /json       Item
+	GET  /item       Item       => item.html
+	GET  /nil        Nil
+	GET  /error      Error
+	GET  /flush      Flush
+	`
+}
+
+func (rc *resultController) View() (string, interface{}, error) {
+	return "show.html", 1, nil
+}
+
+func (rc *resultController) MAV() (ModelAndView, error) {
+	return ModelAndView{View: "mav.html", Model: "m"}, nil
+}
+
+func (rc *resultController) MAVPtr() (*ModelAndView, error) {
+	return &ModelAndView{View: "mav.html", Model: "p"}, nil
+}
+
+func (rc *resultController) Status() (*ModelAndView, error) {
+	return &ModelAndView{View: "form.html", Model: "bad", Status: http.StatusUnprocessableEntity}, nil
+}
+
+func (rc *resultController) Redirect() (Redirect, error) {
+	return Redirect{URL: "/list"}, nil
+}
+
+func (rc *resultController) SeeOther() (*Redirect, error) {
+	return &Redirect{URL: "/list", Status: http.StatusSeeOther}, nil
+}
+
+func (rc *resultController) Bytes() ([]byte, error) {
+	return []byte("raw"), nil
+}
+
+func (rc *resultController) String() (string, error) {
+	return "text", nil
+}
+
+func (rc *resultController) Item() (*resultItem, error) {
+	return &resultItem{Name: "pen"}, nil
+}
+
+func (rc *resultController) Nil() (*resultItem, error) {
+	return nil, nil
+}
+
+func (rc *resultController) Error() (*resultItem, error) {
+	return &resultItem{Name: "ignored"}, gmvc.NewStatusError(http.StatusTeapot, errors.New("teapot"))
+}
+
+func (rc *resultController) Flush() (*ModelAndView, error) {
+	return &ModelAndView{View: "flush", Status: http.StatusAccepted}, nil
+}
+
+type flushView struct {
+	testView
+}
+
+func (v *flushView) Render(c *gmvc.Context, name string, data interface{}) error {
+	if name != "flush" {
+		return v.testView.Render(c, name, data)
+	}
+
+	c.ResponseWriter.WriteHeader(http.StatusOK)
+	io.WriteString(c.ResponseWriter, "chunk")
+	f, ok := c.ResponseWriter.(http.Flusher)
+	if !ok {
+		return errors.New("no flusher")
+	}
+	f.Flush()
+
+	if _, ok := c.ResponseWriter.(interface{ Unwrap() http.ResponseWriter }); !ok {
+		return errors.New("no Unwrap")
+	}
+	return nil
+}
+
+func TestResults(t *testing.T) {
+	app := gmvc.NewApp()
+	app.View = &flushView{}
+	if err := Register(app.Router, "/", &resultController{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target      string
+		status      int
+		body        string
+		location    string
+		contentType string
+	}{
+		{"/view", http.StatusOK, "show.html: 1", "", ""},
+		{"/mav", http.StatusOK, "mav.html: m", "", ""},
+		{"/mavptr", http.StatusOK, "mav.html: p", "", ""},
+		{"/status", http.StatusUnprocessableEntity, "form.html: bad", "", ""},
+		{"/redirect", http.StatusFound, "", "/list", ""},
+		{"/see", http.StatusSeeOther, "", "/list", ""},
+		{"/bytes", http.StatusOK, "raw", "", ""},
+		{"/string", http.StatusOK, "text", "", ""},
+		{"/json", http.StatusOK, `{"name":"pen"}`, "", "application/json"},
+		{"/item", http.StatusOK, "item.html: &{pen}", "", ""},
+		{"/nil", http.StatusOK, "null", "", "application/json"},
+		{"/error", http.StatusTeapot, "", "", ""},
+		{"/flush", http.StatusAccepted, "chunk", "", ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.target, w.Code, tt.status)
+		}
+		if tt.location == "" && tt.status < 400 && strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.target, w.Body.String(), tt.body)
+		}
+		if l := w.Header().Get("Location"); l != tt.location {
+			t.Errorf("%s: location = %q, want %q", tt.target, l, tt.location)
+		}
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: content type = %q", tt.target, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestResultSignatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+	}{
+		{"view name not string", func() (int, interface{}, error) { return 0, nil, nil }},
+		{"too many results", func() (string, int, int, error) { return "", 0, 0, nil }},
+	}
+
+	for _, tt := range tests {
+		if err := RegisterRoutes(gmvc.NewRouter(), "/", routesOf{{Pattern: "/", Handler: tt.handler}}); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
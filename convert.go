@@ -0,0 +1,197 @@
+package gmvc
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	typeOfTime            reflect.Type = reflect.TypeOf(time.Time{})
+	typeOfDuration                     = reflect.TypeOf(time.Duration(0))
+	typeOfTextUnmarshaler              = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+)
+
+var (
+	timeLayouts = []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+)
+
+type Source interface {
+	Lookup(key string) ([]string, bool)
+}
+
+func Get[T any](src Source, key string, def T) (T, bool, error) {
+	ss, ok := src.Lookup(key)
+	if !ok || len(ss) == 0 {
+		return def, ok, nil
+	}
+
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if ss[0] == "" && rv.Kind() != reflect.String {
+		return def, ok, nil
+	}
+
+	if err := setString(rv, ss[0], ""); err != nil {
+		return def, ok, err
+	}
+
+	return v, ok, nil
+}
+
+func GetAll[T any](src Source, key string) ([]T, bool, error) {
+	ss, ok := src.Lookup(key)
+	if !ok {
+		return nil, ok, nil
+	}
+
+	vs := make([]T, len(ss))
+	for i, s := range ss {
+		if err := setString(reflect.ValueOf(&vs[i]).Elem(), s, ""); err != nil {
+			return nil, ok, err
+		}
+	}
+
+	return vs, ok, nil
+}
+
+func Parse(s string, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("parse destination must be a non-nil pointer, got %T", dst)
+	}
+	return setString(rv.Elem(), s, "")
+}
+
+func setValue(v reflect.Value, ss []string, layout string) error {
+	t := v.Type()
+
+	if t.Kind() == reflect.Ptr {
+		nv := reflect.New(t.Elem())
+		if err := setValue(nv.Elem(), ss, layout); err != nil {
+			return err
+		}
+		v.Set(nv)
+		return nil
+	}
+
+	if t.Kind() == reflect.Slice && t != typeOfTime && !reflect.PtrTo(t).Implements(typeOfTextUnmarshaler) {
+		sv := reflect.MakeSlice(t, len(ss), len(ss))
+		for i, s := range ss {
+			if err := setValue(sv.Index(i), []string{s}, layout); err != nil {
+				return err
+			}
+		}
+		v.Set(sv)
+		return nil
+	}
+
+	return setString(v, ss[0], layout)
+}
+
+func setString(v reflect.Value, s string, layout string) error {
+	t := v.Type()
+
+	if t == typeOfTime {
+		tm, err := parseTime(s, layout)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	if v.CanAddr() && reflect.PtrTo(t).Implements(typeOfTextUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		if s == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		if t == typeOfDuration {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	return nil
+}
+
+func parseTime(s string, layout string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if layout != "" {
+		return time.Parse(layout, s)
+	}
+
+	for _, l := range timeLayouts {
+		if tm, err := time.Parse(l, s); err == nil {
+			return tm, nil
+		}
+	}
+
+	return time.Time{}, errors.New("unrecognized time format '" + s + "'")
+}
@@ -0,0 +1,107 @@
+package gmvc
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	values := Values{
+		"n":     {"42", "7"},
+		"neg":   {"-3"},
+		"big":   {"300"},
+		"f":     {"1.5"},
+		"b":     {"true"},
+		"s":     {"pen"},
+		"empty": {""},
+		"day":   {"2024-05-06"},
+		"d":     {"1m30s"},
+		"ip":    {"10.0.0.1"},
+		"bad":   {"x"},
+	}
+
+	check := func(name string, got interface{}, ok bool, err error, want interface{}, wantOk bool, wantErr bool) {
+		t.Helper()
+		if (err != nil) != wantErr {
+			t.Errorf("%s: error = %v, want error %v", name, err, wantErr)
+		}
+		if ok != wantOk {
+			t.Errorf("%s: ok = %v, want %v", name, ok, wantOk)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: value = %v, want %v", name, got, want)
+		}
+	}
+
+	n, ok, err := Get[int](values, "n", 1)
+	check("int", n, ok, err, 42, true, false)
+	i8, ok, err := Get[int8](values, "neg", 0)
+	check("int8", i8, ok, err, int8(-3), true, false)
+	u8, ok, err := Get[uint8](values, "big", 9)
+	check("uint8 overflow", u8, ok, err, uint8(9), true, true)
+	f, ok, err := Get[float64](values, "f", 0)
+	check("float64", f, ok, err, 1.5, true, false)
+	b, ok, err := Get[bool](values, "b", false)
+	check("bool", b, ok, err, true, true, false)
+	s, ok, err := Get[string](values, "s", "")
+	check("string", s, ok, err, "pen", true, false)
+	s, ok, err = Get[string](values, "empty", "def")
+	check("empty string", s, ok, err, "", true, false)
+	n, ok, err = Get[int](values, "empty", 5)
+	check("empty int", n, ok, err, 5, true, false)
+	n, ok, err = Get[int](values, "missing", 5)
+	check("missing", n, ok, err, 5, false, false)
+	n, ok, err = Get[int](values, "bad", 5)
+	check("bad int", n, ok, err, 5, true, true)
+	day, ok, err := Get[time.Time](values, "day", time.Time{})
+	check("time", day, ok, err, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), true, false)
+	d, ok, err := Get[time.Duration](values, "d", 0)
+	check("duration", d, ok, err, 90*time.Second, true, false)
+	ip, ok, err := Get[net.IP](values, "ip", nil)
+	check("text unmarshaler", ip.String(), ok, err, "10.0.0.1", true, false)
+
+	ns, ok, err := GetAll[int](values, "n")
+	check("all ints", ns, ok, err, []int{42, 7}, true, false)
+	ns, ok, err = GetAll[int](values, "missing")
+	check("all missing", ns, ok, err, []int(nil), false, false)
+	ns, ok, err = GetAll[int](values, "bad")
+	check("all bad", ns, ok, err, []int(nil), true, true)
+
+	vars := PathVars{"id": "12"}
+	n, ok, err = Get[int](vars, "id", 0)
+	check("path var", n, ok, err, 12, true, false)
+	ns, ok, err = GetAll[int](vars, "id")
+	check("all path var", ns, ok, err, []int{12}, true, false)
+}
+
+func TestValuesAccessors(t *testing.T) {
+	values := Values{"n": {"1", "2"}, "f": {"2.5"}}
+
+	if n := values.Int("n"); n != 1 {
+		t.Errorf("Int = %d, want 1", n)
+	}
+	if f := values.Float32("f"); f != 2.5 {
+		t.Errorf("Float32 = %v, want 2.5", f)
+	}
+	if u8s, _, _ := values.GetUint8s("n"); !reflect.DeepEqual(u8s, []uint8{1, 2}) {
+		t.Errorf("GetUint8s = %v, want [1 2]", u8s)
+	}
+	if n := (PathVars{"id": "3"}).Uint16("id"); n != 3 {
+		t.Errorf("PathVars.Uint16 = %d, want 3", n)
+	}
+	if n := (PathVars(nil)).Int("id"); n != 0 {
+		t.Errorf("nil PathVars.Int = %d, want 0", n)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var d time.Duration
+	if err := Parse("2s", &d); err != nil || d != 2*time.Second {
+		t.Errorf("Parse = %v, %v", d, err)
+	}
+	if err := Parse("2s", d); err == nil {
+		t.Error("Parse into a non-pointer returns no error")
+	}
+}
@@ -1,20 +1,56 @@
 package gmvc
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 type ErrorHandler interface {
 	HandleError(c *Context, err error, status int)
 }
 
+type StatusError interface {
+	error
+	Status() int
+}
+
 type defaultErrorHandler struct {
 }
 
 func (h *defaultErrorHandler) HandleError(c *Context, err error, status int) {
+	if errs, ok := err.(ValidationErrors); ok {
+		h.handleValidation(c, errs, status)
+		return
+	}
+
 	if err == nil {
 		http.Error(c.ResponseWriter, "", status)
 	} else {
 		http.Error(c.ResponseWriter, err.Error(), status)
 	}
 }
+
+func (h *defaultErrorHandler) handleValidation(c *Context, errs ValidationErrors, status int) {
+	r := c.Request
+	if !strings.Contains(r.Header.Get("Accept"), "json") && !strings.Contains(r.Header.Get("Content-Type"), "json") {
+		lines := make([]string, len(errs))
+		for i, fe := range errs {
+			lines[i] = fe.Error()
+		}
+		http.Error(c.ResponseWriter, strings.Join(lines, "\n"), status)
+		return
+	}
+
+	b, err := json.Marshal(map[string]interface{}{"errors": errs})
+	if err != nil {
+		http.Error(c.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	header := c.ResponseWriter.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("X-Content-Type-Options", "nosniff")
+	c.ResponseWriter.WriteHeader(status)
+	c.ResponseWriter.Write(b)
+}
@@ -1,5 +1,10 @@
 package gmvc
 
+import (
+	"mime"
+	"strings"
+)
+
 type Filter interface {
 	DoFilter(fc *FilterContext) error
 }
@@ -9,3 +14,76 @@ type FilterFunc func(*FilterContext) error
 func (f FilterFunc) DoFilter(fc *FilterContext) error {
 	return f(fc)
 }
+
+func WithFilters(handler Handler, filters ...Filter) Handler {
+	if len(filters) == 0 {
+		return handler
+	}
+
+	fs := make([]*filter, len(filters))
+	for i, f := range filters {
+		fs[i] = &filter{filter: f}
+	}
+	routes := []route{&finalRoute{handler}}
+
+	return HandlerFunc(func(c *Context) error {
+		ch := newChain(c, "", fs, routes, c.Vars)
+		ch.halt = true
+		_, err := ch.next()
+		return err
+	})
+}
+
+type finalRoute struct {
+	handler Handler
+}
+
+func (r *finalRoute) match(c *Context, urlpath string, vars PathVars) (bool, error) {
+	return true, r.handler.HandleRequest(c)
+}
+
+const (
+	MethodOverrideField  = "_method"
+	MethodOverrideHeader = "X-HTTP-Method-Override"
+)
+
+// MethodOverride changes a POST request to the method in the _method form
+// field or the X-HTTP-Method-Override header. The form field is only read
+// from urlencoded bodies, multipart bodies are left unread for streaming
+// uploads, so they can only be overridden by the header.
+func MethodOverride(methods ...string) Filter {
+	if len(methods) == 0 {
+		methods = []string{"PUT", "PATCH", "DELETE"}
+	}
+
+	allowed := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		allowed[strings.ToUpper(m)] = true
+	}
+
+	return FilterFunc(func(fc *FilterContext) error {
+		r := fc.Context.Request
+		if r.Method != "POST" {
+			return fc.Next()
+		}
+
+		m := r.Header.Get(MethodOverrideHeader)
+		if m == "" && isURLEncoded(r.Header.Get("Content-Type")) {
+			form, err := fc.Context.PostForm()
+			if err != nil {
+				return err
+			}
+			m = form.Get(MethodOverrideField)
+		}
+
+		if m = strings.ToUpper(strings.TrimSpace(m)); allowed[m] {
+			r.Method = m
+		}
+		return fc.Next()
+	})
+}
+
+func isURLEncoded(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == "application/x-www-form-urlencoded"
+}
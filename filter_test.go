@@ -0,0 +1,93 @@
+package gmvc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMethodOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		header      string
+		body        string
+		filter      Filter
+		want        string
+		status      int
+	}{
+		{"form field", "POST", "application/x-www-form-urlencoded", "", "_method=DELETE", MethodOverride(), "DELETE", http.StatusOK},
+		{"lower case", "POST", "application/x-www-form-urlencoded", "", "_method=put", MethodOverride(), "PUT", http.StatusOK},
+		{"header", "POST", "", "PATCH", "", MethodOverride(), "PATCH", http.StatusOK},
+		{"header first", "POST", "application/x-www-form-urlencoded", "PUT", "_method=DELETE", MethodOverride(), "PUT", http.StatusOK},
+		{"not allowed", "POST", "application/x-www-form-urlencoded", "", "_method=GET", MethodOverride(), "POST", http.StatusOK},
+		{"custom allowlist", "POST", "application/x-www-form-urlencoded", "", "_method=PUT", MethodOverride("DELETE"), "POST", http.StatusOK},
+		{"only post", "GET", "", "DELETE", "", MethodOverride(), "GET", http.StatusOK},
+		{"multipart body unread", "POST", "multipart/form-data; boundary=x", "", "--x--", MethodOverride(), "POST", http.StatusOK},
+		{"malformed form", "POST", "application/x-www-form-urlencoded", "", "_method=%zz", MethodOverride(), "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		app := NewApp()
+		app.Router.Filter("", tt.filter)
+		app.Router.HandleFunc("/", func(c *Context) error {
+			return c.WriteString(c.Request.Method)
+		})
+
+		r := newFormRequest("/", tt.contentType, tt.body)
+		r.Method = tt.method
+		if tt.header != "" {
+			r.Header.Set(MethodOverrideHeader, tt.header)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+			continue
+		}
+		if tt.want != "" && w.Body.String() != tt.want {
+			t.Errorf("%s: method = %s, want %s", tt.name, w.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestFilterError(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterFunc
+		status int
+	}{
+		{"before next", func(fc *FilterContext) error {
+			return NewStatusError(http.StatusForbidden, errors.New("forbidden"))
+		}, http.StatusForbidden},
+		{"after next", func(fc *FilterContext) error {
+			fc.Next()
+			return errors.New("after")
+		}, http.StatusInternalServerError},
+		{"passed", func(fc *FilterContext) error {
+			return fc.Next()
+		}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		ran := false
+		app := NewApp()
+		app.Router.FilterFunc("/**", tt.filter)
+		app.Router.HandleFunc("/x", func(c *Context) error {
+			ran = true
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/x", nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if tt.name == "before next" && ran {
+			t.Errorf("%s: handler runs after the filter error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package gmvc
+
+import (
+	"fmt"
+)
+
+const (
+	flashKey = "gmvc.flash"
+)
+
+type FlashMessage struct {
+	Kind    string
+	Message string
+}
+
+type Flash struct {
+	context *Context
+}
+
+func (c *Context) Flash() *Flash {
+	return &Flash{context: c}
+}
+
+func (f *Flash) Add(kind string, message string) error {
+	s, err := f.context.Session(true)
+	if err != nil {
+		return err
+	}
+
+	values, err := loadFlashes(s)
+	if err != nil {
+		return err
+	}
+
+	values = append(values[:len(values):len(values)], kind, message)
+	return s.Set(flashKey, values)
+}
+
+func (f *Flash) Pop(kind string) ([]string, error) {
+	msgs, err := f.PopAll(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+	for _, m := range msgs {
+		ss = append(ss, m.Message)
+	}
+	return ss, nil
+}
+
+func (f *Flash) PopAll(kinds ...string) ([]FlashMessage, error) {
+	s, err := f.context.Session(false)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	values, err := loadFlashes(s)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	var msgs []FlashMessage
+	var rest []string
+	for i := 0; i+1 < len(values); i += 2 {
+		kind, message := values[i], values[i+1]
+		if matchKind(kind, kinds) {
+			msgs = append(msgs, FlashMessage{Kind: kind, Message: message})
+		} else {
+			rest = append(rest, kind, message)
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+
+	if len(rest) == 0 {
+		err = s.Del(flashKey)
+	} else {
+		err = s.Set(flashKey, rest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+func matchKind(kind string, kinds []string) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func loadFlashes(s Session) ([]string, error) {
+	v, err := s.Get(flashKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		ss := make([]string, len(v))
+		for i, e := range v {
+			ss[i] = fmt.Sprint(e)
+		}
+		return ss, nil
+	}
+
+	return nil, fmt.Errorf("unexpected flash value of type %T in session", v)
+}
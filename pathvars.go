@@ -1,9 +1,5 @@
 package gmvc
 
-import (
-	"strconv"
-)
-
 type PathVars map[string]string
 
 func (p PathVars) Get(key string) string {
@@ -13,142 +9,115 @@ func (p PathVars) Get(key string) string {
 	return p[key]
 }
 
-// string
-
-func (p PathVars) String(key string) string {
-	return p.Get(key)
-}
-
-// integer
-
-func (p PathVars) getInt(key string, bitsize int) int64 {
+func (p PathVars) Lookup(key string) ([]string, bool) {
 	s, ok := p[key]
-	if !ok || s == "" {
-		return 0
+	if !ok {
+		return nil, false
 	}
-
-	v, err := strconv.ParseInt(s, 10, bitsize)
-	if err != nil {
-		return 0
-	}
-
-	return v
+	return []string{s}, true
 }
 
-func (p PathVars) getUint(key string, bitsize int) uint64 {
-	s, ok := p[key]
-	if !ok || s == "" {
-		return 0
+func (p PathVars) values() map[string][]string {
+	values := make(map[string][]string, len(p))
+	for k, v := range p {
+		values[k] = []string{v}
 	}
+	return values
+}
 
-	v, err := strconv.ParseUint(s, 10, bitsize)
-	if err != nil {
-		return 0
-	}
+// string
 
-	return v
+func (p PathVars) String(key string) string {
+	return p.Get(key)
 }
 
-//Int
+// Int
 
 func (p PathVars) Int(key string) int {
-	return int(p.getInt(key, 0))
+	v, _, _ := Get[int](p, key, 0)
+	return v
 }
 
 // Int8
 
 func (p PathVars) Int8(key string) int8 {
-	return int8(p.getInt(key, 8))
+	v, _, _ := Get[int8](p, key, 0)
+	return v
 }
 
 // Int16
 
 func (p PathVars) Int16(key string) int16 {
-	return int16(p.getInt(key, 16))
+	v, _, _ := Get[int16](p, key, 0)
+	return v
 }
 
 // Int32
 
 func (p PathVars) Int32(key string) int32 {
-	return int32(p.getInt(key, 32))
+	v, _, _ := Get[int32](p, key, 0)
+	return v
 }
 
 // Int64
 
 func (p PathVars) Int64(key string) int64 {
-	return p.getInt(key, 64)
+	v, _, _ := Get[int64](p, key, 0)
+	return v
 }
 
-// uint
+// Uint
 
 func (p PathVars) Uint(key string) uint {
-	return uint(p.getUint(key, 0))
+	v, _, _ := Get[uint](p, key, 0)
+	return v
 }
 
 // Uint8
 
 func (p PathVars) Uint8(key string) uint8 {
-	return uint8(p.getUint(key, 8))
+	v, _, _ := Get[uint8](p, key, 0)
+	return v
 }
 
 // Uint16
 
 func (p PathVars) Uint16(key string) uint16 {
-	return uint16(p.getUint(key, 16))
+	v, _, _ := Get[uint16](p, key, 0)
+	return v
 }
 
 // Uint32
 
 func (p PathVars) Uint32(key string) uint32 {
-	return uint32(p.getUint(key, 32))
+	v, _, _ := Get[uint32](p, key, 0)
+	return v
 }
 
 // Uint64
 
 func (p PathVars) Uint64(key string) uint64 {
-	return p.getUint(key, 64)
-}
-
-// float
-
-func (p PathVars) getFloat(key string, bitsize int) float64 {
-	s, ok := p[key]
-	if !ok || s == "" {
-		return 0
-	}
-
-	v, err := strconv.ParseFloat(s, bitsize)
-	if err != nil {
-		return 0
-	}
-
+	v, _, _ := Get[uint64](p, key, 0)
 	return v
 }
 
 // Float32
 
 func (p PathVars) Float32(key string) float32 {
-	return float32(p.getFloat(key, 32))
+	f, _, _ := Get[float32](p, key, 0)
+	return f
 }
 
 // Float64
 
 func (p PathVars) Float64(key string) float64 {
-	return p.getFloat(key, 64)
+	f, _, _ := Get[float64](p, key, 0)
+	return f
 }
 
 // bool
 
 func (p PathVars) Bool(key string) bool {
-	s, ok := p[key]
-	if !ok || s == "" {
-		return false
-	}
-
-	v, err := strconv.ParseBool(s)
-	if err != nil {
-		return false
-	}
-
-	return v
+	b, _, _ := Get[bool](p, key, false)
+	return b
 }
@@ -0,0 +1,104 @@
+package gmvc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirect(t *testing.T) {
+	app := NewApp()
+	app.RedirectHosts = []string{"accounts.example.com", "*.example.org"}
+
+	var target string
+	app.Router.HandleFunc("/user/{path:.*}", func(c *Context) error {
+		return c.Redirect(target, http.StatusFound)
+	})
+
+	tests := []struct {
+		target   string
+		location string
+	}{
+		{"/login", "/login"},
+		{"/login/", "/login/"},
+		{"edit?a=1#top", "/user/a/edit?a=1#top"},
+		{"../list", "/user/list"},
+		{"http://www.example.com/x", "http://www.example.com/x"},
+		{"https://accounts.example.com/", "https://accounts.example.com/"},
+		{"https://a.b.example.org/", "https://a.b.example.org/"},
+		{"https://ACCOUNTS.example.com/", "https://ACCOUNTS.example.com/"},
+
+		{"//evil.com/x", ""},
+		{"///evil.com", "/evil.com"},
+		{"/\\evil.com", ""},
+		{"https://evil.com/", ""},
+		{"https://evilexample.com/", ""},
+		{"https://example.org/", ""},
+		{"https://evilexample.org/", ""},
+		{"https://accounts.example.com.evil.com/", ""},
+		{"javascript:alert(1)", ""},
+		{"JavaScript:alert(1)", ""},
+		{"data:text/html,x", ""},
+		{"ftp://www.example.com/", ""},
+	}
+
+	for _, tt := range tests {
+		target = tt.target
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "http://www.example.com/user/a/b", nil))
+
+		if tt.location == "" {
+			if w.Code != http.StatusInternalServerError || w.Header().Get("Location") != "" {
+				t.Errorf("Redirect(%q) = %d %q, want rejected", tt.target, w.Code, w.Header().Get("Location"))
+			}
+			continue
+		}
+		if w.Code != http.StatusFound || w.Header().Get("Location") != tt.location {
+			t.Errorf("Redirect(%q) = %d %q, want %q", tt.target, w.Code, w.Header().Get("Location"), tt.location)
+		}
+	}
+}
+
+func TestRedirectAppPath(t *testing.T) {
+	app := NewApp()
+	app.Path = "/app"
+	app.Router.HandleFunc("/a/b", func(c *Context) error {
+		return c.Redirect("/login", http.StatusFound)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/app/a/b", nil))
+	if loc := w.Header().Get("Location"); loc != "/app/login" {
+		t.Errorf("Location = %q, want /app/login", loc)
+	}
+}
+
+func TestRedirectBack(t *testing.T) {
+	app := NewApp()
+	app.Router.HandleFunc("/", func(c *Context) error {
+		return c.RedirectBack("/home", http.StatusFound)
+	})
+
+	tests := []struct {
+		referer  string
+		location string
+	}{
+		{"", "/home"},
+		{"http://www.example.com/page?x=1", "http://www.example.com/page?x=1"},
+		{"http://evil.com/page", "/home"},
+		{"javascript:alert(1)", "/home"},
+		{"/relative", "/home"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://www.example.com/", nil)
+		if tt.referer != "" {
+			r.Header.Set("Referer", tt.referer)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if loc := w.Header().Get("Location"); loc != tt.location {
+			t.Errorf("RedirectBack with Referer %q = %q, want %q", tt.referer, loc, tt.location)
+		}
+	}
+}
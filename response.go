@@ -0,0 +1,78 @@
+package gmvc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+type responseWriter struct {
+	http.ResponseWriter
+	beforeCommit func() error
+	committed    bool
+	err          error
+}
+
+// commit runs beforeCommit once before the first write. If it fails, the
+// error response is already written, later writes of the handler are
+// dropped and return the error.
+func (w *responseWriter) commit() error {
+	if !w.committed {
+		w.committed = true
+		if w.beforeCommit != nil {
+			w.err = w.beforeCommit()
+		}
+	}
+	return w.err
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.commit() == nil {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if err := w.commit(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if err := w.commit(); err != nil {
+		return 0, err
+	}
+	return io.Copy(w.ResponseWriter, r)
+}
+
+func (w *responseWriter) Flush() {
+	w.commit()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	if err := w.commit(); err != nil {
+		return nil, nil, err
+	}
+	return h.Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
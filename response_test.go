@@ -0,0 +1,148 @@
+package gmvc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errSave = errors.New("disk full")
+
+type saveSession struct {
+	Session
+	saves int
+	fail  int
+}
+
+func (s *saveSession) Valid() bool {
+	return true
+}
+
+func (s *saveSession) Save() error {
+	s.saves++
+	if s.saves == s.fail {
+		return errSave
+	}
+	return nil
+}
+
+type saveProvider struct {
+	session *saveSession
+}
+
+func (p *saveProvider) GetSession(w http.ResponseWriter, r *http.Request, create bool) (Session, error) {
+	return p.session, nil
+}
+
+func TestSessionSaveError(t *testing.T) {
+	tests := []struct {
+		name   string
+		write  bool
+		fail   int
+		status int
+		body   string
+		logged bool
+	}{
+		{"saved", true, 0, http.StatusOK, "body", false},
+		{"before write", true, 1, http.StatusInternalServerError, "disk full", false},
+		{"no write", false, 1, http.StatusInternalServerError, "disk full", false},
+		{"after write", true, 2, http.StatusOK, "body", true},
+	}
+
+	for _, tt := range tests {
+		var logs bytes.Buffer
+		app := NewApp()
+		app.ErrorLog = log.New(&logs, "", 0)
+		app.SessionProvider = &saveProvider{&saveSession{fail: tt.fail}}
+
+		var writeErr error
+		app.Router.HandleFunc("/", func(c *Context) error {
+			c.Session(true)
+			if tt.write {
+				_, writeErr = io.WriteString(c.ResponseWriter, "body")
+			}
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != tt.status || strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Errorf("%s: response = %d %q, want %d %q", tt.name, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if tt.write && (writeErr != nil) != (tt.status != http.StatusOK) {
+			t.Errorf("%s: handler write returns %v", tt.name, writeErr)
+		}
+		if logged := strings.Contains(logs.String(), errSave.Error()); logged != tt.logged {
+			t.Errorf("%s: log = %q", tt.name, logs.String())
+		}
+	}
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed string
+}
+
+func (w *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	w.pushed = target
+	return nil
+}
+
+func TestResponseWriter(t *testing.T) {
+	committed := 0
+	rec := httptest.NewRecorder()
+	rw := &responseWriter{ResponseWriter: rec, beforeCommit: func() error {
+		committed++
+		return nil
+	}}
+
+	var w http.ResponseWriter = rw
+	if _, ok := w.(interface{ Unwrap() http.ResponseWriter }); !ok {
+		t.Error("no Unwrap")
+	}
+	if err := w.(http.Pusher).Push("/app.js", nil); err != http.ErrNotSupported {
+		t.Errorf("Push returns %v, want ErrNotSupported", err)
+	}
+	if _, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("abc")); err != nil {
+		t.Fatal(err)
+	}
+	w.(http.Flusher).Flush()
+
+	if committed != 1 || rec.Body.String() != "abc" || !rec.Flushed {
+		t.Errorf("commits = %d, body = %q, flushed = %v", committed, rec.Body.String(), rec.Flushed)
+	}
+
+	pusher := &pushRecorder{ResponseRecorder: rec}
+	rw = &responseWriter{ResponseWriter: pusher}
+	if err := rw.Push("/app.js", nil); err != nil || pusher.pushed != "/app.js" {
+		t.Errorf("Push returns %v, pushed %q", err, pusher.pushed)
+	}
+}
+
+func TestBodyLimitClosesConnection(t *testing.T) {
+	app := NewApp()
+	app.MaxBodySize = 8
+	app.Router.HandleFunc("/", func(c *Context) error {
+		_, err := c.Form()
+		return err
+	})
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader("a=0123456789"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || !resp.Close {
+		t.Errorf("response = %d, close = %v, want 413 and the connection closed", resp.StatusCode, resp.Close)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"path"
 	"regexp"
 	"strings"
@@ -27,13 +28,20 @@ type route interface {
 type Router struct {
 	filters []*filter
 	routes  []route
+	root    *Router
+	prefix  string
+	names   map[string]string
 }
 
 func NewRouter() *Router {
-	return &Router{
+	rt := &Router{
 		filters: make([]*filter, 0),
 		routes:  make([]route, 0),
+		prefix:  "/",
+		names:   make(map[string]string),
 	}
+	rt.root = rt
+	return rt
 }
 
 func (rt *Router) Subrouter(pattern string) (*Router, error) {
@@ -42,6 +50,10 @@ func (rt *Router) Subrouter(pattern string) (*Router, error) {
 	}
 
 	srt := NewRouter()
+	srt.root = rt.rootRouter()
+	srt.prefix = joinPattern(rt.prefix, pattern)
+	srt.names = nil
+
 	sr, err := newSubroutes(pattern, srt)
 	if err != nil {
 		return nil, err
@@ -117,6 +129,63 @@ func (rt *Router) HandleFunc(pattern string, f HandlerFunc) error {
 	return rt.Handle(pattern, f)
 }
 
+func (rt *Router) HandleNamed(name string, pattern string, handler Handler) error {
+	values := regexHandlerPattern.FindStringSubmatch(pattern)
+	if values == nil {
+		return fmt.Errorf("incorrect format pattern for handler: %s, syntax: %s", pattern, handlerPatternSyntax)
+	}
+
+	if err := rt.Name(name, values[2]); err != nil {
+		return err
+	}
+
+	return rt.Handle(pattern, handler)
+}
+
+func (rt *Router) HandleFuncNamed(name string, pattern string, f HandlerFunc) error {
+	return rt.HandleNamed(name, pattern, f)
+}
+
+func (rt *Router) Name(name string, pathPattern string) error {
+	if name == "" {
+		return errors.New("empty route name")
+	}
+
+	root := rt.rootRouter()
+	if root.names == nil {
+		root.names = make(map[string]string)
+	}
+	if _, ok := root.names[name]; ok {
+		return fmt.Errorf("Conflicting route name '%s'", name)
+	}
+
+	root.names[name] = joinPattern(rt.prefix, pathPattern)
+	return nil
+}
+
+func (rt *Router) URL(name string, vars PathVars) (string, error) {
+	pattern, ok := rt.rootRouter().names[name]
+	if !ok {
+		return "", fmt.Errorf("no route named '%s'", name)
+	}
+	return expandPattern(pattern, vars)
+}
+
+func (rt *Router) rootRouter() *Router {
+	if rt.root == nil {
+		return rt
+	}
+	return rt.root
+}
+
+func joinPattern(prefix string, pattern string) string {
+	p := path.Join("/", prefix, pattern)
+	if strings.HasSuffix(pattern, "/") && p != "/" {
+		p += "/"
+	}
+	return p
+}
+
 func (rt *Router) route(c *Context, urlpath string, vars PathVars) (bool, error) {
 	return newChain(c, urlpath, rt.filters, rt.routes, vars).next()
 }
@@ -129,6 +198,7 @@ type chain struct {
 	vars    PathVars
 	pos     int
 	tail    bool
+	halt    bool
 }
 
 func newChain(context *Context, urlpath string, filters []*filter, routes []route, vars PathVars) *chain {
@@ -160,7 +230,7 @@ func (c *chain) next() (bool, error) {
 		f := c.filters[c.pos]
 		c.pos++
 
-		if match, err := f.match(c); match {
+		if match, err := f.match(c); match || c.halt || err != nil {
 			return true, err
 		}
 	}
@@ -281,6 +351,7 @@ func newHandlerRoute(pattern string) (*handlerRoute, error) {
 	}
 
 	return &handlerRoute{
+		pattern:  pattern,
 		tpl:      tpl,
 		handlers: make(map[string]Handler),
 	}, nil
@@ -319,6 +390,69 @@ func (hr *handlerRoute) match(c *Context, urlpath string, vars PathVars) (bool,
 	return false, nil
 }
 
+func expandPattern(pattern string, vars PathVars) (string, error) {
+	buf := new(bytes.Buffer)
+
+	for _, m := range regexPart.FindAllStringSubmatch(pattern, -1) {
+		part := m[1]
+		if part == "" {
+			continue
+		}
+
+		buf.WriteString("/")
+		locs := regexGlob.FindAllStringIndex(part, -1)
+
+		var e int
+		for _, loc := range locs {
+			buf.WriteString(part[e:loc[0]])
+
+			g := part[loc[0]:loc[1]]
+			e = loc[1]
+
+			if !strings.HasPrefix(g, "{") {
+				return "", fmt.Errorf("can not expand wildcard '%s' in pattern '%s'", g, pattern)
+			}
+
+			g = strings.Replace(g, "\\}", "}", -1)
+			var k, v string
+			i := strings.Index(g, ":")
+			if i == -1 {
+				k = g[1 : len(g)-1]
+				v = "[^/]+"
+			} else {
+				k = g[1:i]
+				v = g[i+1 : len(g)-1]
+			}
+
+			value, ok := vars[k]
+			if k == "" || !ok {
+				return "", fmt.Errorf("missing var '%s' for pattern '%s'", k, pattern)
+			}
+
+			regex, err := regexp.Compile("^(?:" + v + ")$")
+			if err != nil {
+				return "", err
+			}
+			if !regex.MatchString(value) {
+				return "", fmt.Errorf("var '%s' value '%s' does not match '%s'", k, value, v)
+			}
+
+			buf.WriteString(url.PathEscape(value))
+		}
+
+		buf.WriteString(part[e:])
+	}
+
+	if buf.Len() == 0 {
+		return "/", nil
+	}
+	if strings.HasSuffix(pattern, "/") {
+		buf.WriteString("/")
+	}
+
+	return buf.String(), nil
+}
+
 type pathTemplate struct {
 	regex   *regexp.Regexp
 	prefix  bool
@@ -0,0 +1,69 @@
+package gmvc
+
+import (
+	"testing"
+)
+
+func TestRouterURL(t *testing.T) {
+	rt := NewRouter()
+	sub, err := rt.Subrouter("/users/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []struct {
+		router  *Router
+		name    string
+		pattern string
+	}{
+		{rt, "home", "/"},
+		{rt, "about", "/about/"},
+		{sub, "user", "/{id:[0-9]+}"},
+		{sub, "user.photos", "/{id}/photos/"},
+		{sub, "users", "/"},
+	}
+	for _, n := range names {
+		if err := n.router.Name(n.name, n.pattern); err != nil {
+			t.Fatalf("Name(%s): %v", n.name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		vars PathVars
+		want string
+		err  bool
+	}{
+		{"home", nil, "/", false},
+		{"about", nil, "/about/", false},
+		{"user", PathVars{"id": "12"}, "/users/12", false},
+		{"user", PathVars{"id": "x"}, "", true},
+		{"user", nil, "", true},
+		{"user.photos", PathVars{"id": "a b"}, "/users/a%20b/photos/", false},
+		{"users", nil, "/users/", false},
+		{"missing", nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := sub.URL(tt.name, tt.vars)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v, want %q, error %v", tt.name, tt.vars, got, err, tt.want, tt.err)
+		}
+	}
+
+	if err := rt.Name("user", "/other"); err == nil {
+		t.Error("Name accepts a conflicting name")
+	}
+}
+
+func TestZeroRouterURL(t *testing.T) {
+	var rt Router
+	if _, err := rt.URL("x", nil); err == nil {
+		t.Error("URL of unknown name returns no error")
+	}
+	if err := rt.Name("x", "/a/{id}/"); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := rt.URL("x", PathVars{"id": "1"}); err != nil || u != "/a/1/" {
+		t.Errorf("URL = %q, %v, want /a/1/", u, err)
+	}
+}
@@ -2,6 +2,7 @@ package gmvc
 
 import (
 	"net/http"
+	"time"
 )
 
 type SessionProvider interface {
@@ -13,8 +14,16 @@ type Session interface {
 
 	Valid() bool
 	Invalidate() error
+	Regenerate() error
 
 	Save() error
+	Touch() error
+
+	CreatedAt() time.Time
+	LastAccess() time.Time
+	ExpiresAt() time.Time
+	SetTimeout(timeout time.Duration, lifetime time.Duration) error
+	Keys() ([]string, error)
 
 	Set(key string, value interface{}) error
 	Get(key string) (interface{}, error)
@@ -0,0 +1,117 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotSupported = errors.New("operation not supported by session store")
+)
+
+type Metrics struct {
+	Created uint64
+	Expired uint64
+	Evicted uint64
+	Revoked uint64
+}
+
+type EnumerableStore interface {
+	Store
+	Count() (int, error)
+	Each(fn func(id string, r *Record) bool) error
+}
+
+type IndexedStore interface {
+	Store
+	FindByPrincipal(principal string) ([]string, error)
+}
+
+type MetricsStore interface {
+	Store
+	Metrics() Metrics
+}
+
+func (p *Provider) Count() (int, error) {
+	s, ok := p.store.(EnumerableStore)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	return s.Count()
+}
+
+func (p *Provider) Each(fn func(id string, r *Record) bool) error {
+	s, ok := p.store.(EnumerableStore)
+	if !ok {
+		return ErrNotSupported
+	}
+	return s.Each(fn)
+}
+
+func (p *Provider) Revoke(id string) error {
+	deleted, err := p.store.Delete(id)
+	if err != nil || !deleted {
+		return err
+	}
+
+	p.mutex.Lock()
+	p.metrics.Revoked++
+	p.mutex.Unlock()
+	return nil
+}
+
+func (p *Provider) RevokeAllFor(principal string) (int, error) {
+	if principal == "" {
+		return 0, nil
+	}
+
+	ids, err := p.findByPrincipal(principal)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := p.Revoke(id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (p *Provider) Metrics() Metrics {
+	p.mutex.Lock()
+	m := p.metrics
+	p.mutex.Unlock()
+
+	if s, ok := p.store.(MetricsStore); ok {
+		sm := s.Metrics()
+		m.Expired += sm.Expired
+		m.Evicted += sm.Evicted
+	}
+	return m
+}
+
+func (p *Provider) findByPrincipal(principal string) ([]string, error) {
+	if s, ok := p.store.(IndexedStore); ok {
+		return s.FindByPrincipal(principal)
+	}
+
+	var ids []string
+	err := p.Each(func(id string, r *Record) bool {
+		if r.Principal == principal {
+			ids = append(ids, id)
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (p *Provider) principal(values map[string]interface{}) string {
+	if p.PrincipalKey == "" {
+		return ""
+	}
+	if v, ok := values[p.PrincipalKey]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+type Codec interface {
+	Encode(values map[string]interface{}) ([]byte, error)
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+type GobCodec struct{}
+
+func (GobCodec) Encode(values map[string]interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Decode(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(values map[string]interface{}) ([]byte, error) {
+	return json.Marshal(values)
+}
+
+func (JSONCodec) Decode(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,398 @@
+package sessions
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/hujh/gmvc"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	maxCookieSize = 4096
+	timestampLen  = 8
+)
+
+var (
+	ErrCookieTooLarge = errors.New("session cookie too large")
+	errInvalidCookie  = errors.New("invalid session cookie")
+)
+
+type CookieKey struct {
+	HashKey  []byte
+	BlockKey []byte
+}
+
+type cookieCodec struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+type CookieProvider struct {
+	*Options
+	MaxLifetime time.Duration
+
+	timeout time.Duration
+	codecs  []*cookieCodec
+}
+
+func NewCookieProvider(timeout time.Duration, keys ...CookieKey) (*CookieProvider, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no cookie keys")
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	codecs := make([]*cookieCodec, len(keys))
+	for i, k := range keys {
+		if len(k.HashKey) < 32 {
+			return nil, fmt.Errorf("cookie key %d: hash key must be at least 32 bytes", i)
+		}
+
+		codec := &cookieCodec{hashKey: k.HashKey}
+		if k.BlockKey != nil {
+			block, err := aes.NewCipher(k.BlockKey)
+			if err != nil {
+				return nil, fmt.Errorf("cookie key %d: %v", i, err)
+			}
+			aead, err := cipher.NewGCM(block)
+			if err != nil {
+				return nil, fmt.Errorf("cookie key %d: %v", i, err)
+			}
+			codec.aead = aead
+		}
+		codecs[i] = codec
+	}
+
+	return &CookieProvider{
+		Options: &Options{CookieName: defaultCookieName, CookieHttpOnly: true},
+		timeout: timeout,
+		codecs:  codecs,
+	}, nil
+}
+
+func (p *CookieProvider) GetSession(w http.ResponseWriter, r *http.Request, create bool) (gmvc.Session, error) {
+	s := &cookieSession{
+		provider: p,
+		w:        w,
+		valid:    true,
+	}
+
+	if c, err := r.Cookie(p.CookieName); err == nil {
+		if data, issued, key, err := p.decode(c.Value); err == nil {
+			if err := s.unmarshal(data, issued); err == nil && !s.record.Expired(time.Now()) {
+				if key > 0 || time.Since(issued) > s.record.Timeout/2 {
+					s.dirty = true
+				}
+				return s, nil
+			}
+		}
+	}
+
+	if !create {
+		return nil, nil
+	}
+
+	id, err := generateId()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.id = id
+	s.record = &Record{
+		Values:     make(map[string]interface{}),
+		CreatedAt:  now,
+		LastAccess: now,
+		Timeout:    p.timeout,
+		Lifetime:   p.MaxLifetime,
+	}
+	s.dirty = true
+
+	return s, nil
+}
+
+func (p *CookieProvider) cookieMaxAge(r *Record) int {
+	if r.Timeout == p.timeout && r.Lifetime == p.MaxLifetime {
+		return p.CookieMaxAge
+	}
+	return maxAge(r.ExpiresAt())
+}
+
+func (p *CookieProvider) encode(data []byte) (string, error) {
+	codec := p.codecs[0]
+
+	if codec.aead != nil {
+		nonce := make([]byte, codec.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		data = codec.aead.Seal(nonce, nonce, data, []byte(p.CookieName))
+	}
+
+	b := make([]byte, timestampLen, timestampLen+len(data)+sha256.Size)
+	binary.BigEndian.PutUint64(b, uint64(time.Now().Unix()))
+	b = append(b, data...)
+	b = append(b, codec.sign(p.CookieName, b)...)
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (p *CookieProvider) decode(value string) ([]byte, time.Time, int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) < timestampLen+sha256.Size {
+		return nil, time.Time{}, 0, errInvalidCookie
+	}
+
+	payload, mac := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+
+	for i, codec := range p.codecs {
+		if !hmac.Equal(mac, codec.sign(p.CookieName, payload)) {
+			continue
+		}
+
+		issued := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+		if time.Since(issued) < -time.Minute {
+			return nil, time.Time{}, 0, errInvalidCookie
+		}
+
+		data := payload[timestampLen:]
+		if codec.aead != nil {
+			n := codec.aead.NonceSize()
+			if len(data) < n {
+				return nil, time.Time{}, 0, errInvalidCookie
+			}
+			data, err = codec.aead.Open(nil, data[:n], data[n:], []byte(p.CookieName))
+			if err != nil {
+				return nil, time.Time{}, 0, errInvalidCookie
+			}
+		}
+		return data, issued, i, nil
+	}
+
+	return nil, time.Time{}, 0, errInvalidCookie
+}
+
+func (c *cookieCodec) sign(name string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.hashKey)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+type cookieData struct {
+	Id        string
+	Values    map[string]interface{}
+	CreatedAt int64
+	Timeout   time.Duration
+	Lifetime  time.Duration
+}
+
+type cookieSession struct {
+	mutex    sync.Mutex
+	w        http.ResponseWriter
+	provider *CookieProvider
+	id       string
+	record   *Record
+	valid    bool
+	dirty    bool
+}
+
+func (s *cookieSession) Id() string {
+	return s.id
+}
+
+func (s *cookieSession) Valid() bool {
+	return s.valid
+}
+
+func (s *cookieSession) Invalidate() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.valid {
+		s.valid = false
+		s.record.Values = make(map[string]interface{})
+		s.provider.setCookie(s.w, "", -1)
+	}
+	return nil
+}
+
+func (s *cookieSession) Regenerate() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid {
+		return errInvalidated
+	}
+
+	id, err := generateId()
+	if err != nil {
+		return err
+	}
+	s.id = id
+	s.dirty = true
+	return nil
+}
+
+func (s *cookieSession) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid || !s.dirty {
+		return nil
+	}
+
+	s.record.LastAccess = time.Now()
+	value, err := s.encode()
+	if err != nil {
+		return err
+	}
+	s.provider.setCookie(s.w, value, s.provider.cookieMaxAge(s.record))
+	s.dirty = false
+	return nil
+}
+
+func (s *cookieSession) Touch() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.valid {
+		s.dirty = true
+	}
+	return nil
+}
+
+func (s *cookieSession) CreatedAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.CreatedAt
+}
+
+func (s *cookieSession) LastAccess() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.LastAccess
+}
+
+func (s *cookieSession) ExpiresAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.ExpiresAt()
+}
+
+func (s *cookieSession) SetTimeout(timeout time.Duration, lifetime time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid {
+		return errInvalidated
+	}
+
+	if timeout <= 0 {
+		timeout = s.provider.timeout
+	}
+	switch {
+	case lifetime == 0:
+		lifetime = s.provider.MaxLifetime
+	case lifetime < 0:
+		lifetime = 0
+	}
+	s.record.Timeout = timeout
+	s.record.Lifetime = lifetime
+	s.dirty = true
+	return nil
+}
+
+func (s *cookieSession) Keys() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return sortedKeys(s.record.Values), nil
+}
+
+func (s *cookieSession) Set(key string, value interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.record.Values[key] = value
+	s.dirty = true
+	return nil
+}
+
+func (s *cookieSession) Get(key string) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.Values[key], nil
+}
+
+func (s *cookieSession) Del(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.record.Values[key]; ok {
+		delete(s.record.Values, key)
+		s.dirty = true
+	}
+	return nil
+}
+
+func (s *cookieSession) encode() (string, error) {
+	d := &cookieData{
+		Id:        s.id,
+		Values:    s.record.Values,
+		CreatedAt: s.record.CreatedAt.UnixNano(),
+		Timeout:   s.record.Timeout,
+		Lifetime:  s.record.Lifetime,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(d); err != nil {
+		return "", err
+	}
+
+	value, err := s.provider.encode(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	if n := len(s.provider.CookieName) + len(value) + 1; n > maxCookieSize {
+		return "", fmt.Errorf("%w: %d bytes, limit %d", ErrCookieTooLarge, n, maxCookieSize)
+	}
+	return value, nil
+}
+
+func (s *cookieSession) unmarshal(data []byte, issued time.Time) error {
+	var d cookieData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&d); err != nil {
+		return err
+	}
+	if d.Values == nil {
+		d.Values = make(map[string]interface{})
+	}
+	if d.Timeout <= 0 {
+		d.Timeout = s.provider.timeout
+	}
+
+	s.id = d.Id
+	s.record = &Record{
+		Values:     d.Values,
+		CreatedAt:  time.Unix(0, d.CreatedAt),
+		LastAccess: issued,
+		Timeout:    d.Timeout,
+		Lifetime:   d.Lifetime,
+	}
+	return nil
+}
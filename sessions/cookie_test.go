@@ -0,0 +1,181 @@
+package sessions_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/hujh/gmvc"
+	"github.com/hujh/gmvc/sessions"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	hashKey1  = bytes.Repeat([]byte("1"), 32)
+	hashKey2  = bytes.Repeat([]byte("2"), 32)
+	blockKey1 = bytes.Repeat([]byte("b"), 16)
+)
+
+func newCookieProvider(t *testing.T, keys ...sessions.CookieKey) *sessions.CookieProvider {
+	t.Helper()
+	p, err := sessions.NewCookieProvider(time.Hour, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getSession(t *testing.T, p gmvc.SessionProvider, cookie *http.Cookie, create bool) (gmvc.Session, *httptest.ResponseRecorder) {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	s, err := p.GetSession(w, r, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, w
+}
+
+func responseCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func saveCookie(t *testing.T, s gmvc.Session, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return responseCookie(w, "gsessionid")
+}
+
+func TestCookieSession(t *testing.T) {
+	for _, key := range []sessions.CookieKey{
+		{HashKey: hashKey1},
+		{HashKey: hashKey1, BlockKey: blockKey1},
+	} {
+		p := newCookieProvider(t, key)
+
+		s, w := getSession(t, p, nil, true)
+		s.Set("user", "alice")
+		cookie := saveCookie(t, s, w)
+		if cookie == nil {
+			t.Fatal("no session cookie")
+		}
+		if key.BlockKey != nil && strings.Contains(cookie.Value, "alice") {
+			t.Error("encrypted cookie shows the value")
+		}
+
+		s2, _ := getSession(t, p, cookie, false)
+		if s2 == nil {
+			t.Fatal("session is not decoded")
+		}
+		if v, _ := s2.Get("user"); v != "alice" || s2.Id() != s.Id() {
+			t.Errorf("session = %s %v, want %s alice", s2.Id(), v, s.Id())
+		}
+
+		tampered := *cookie
+		c := byte('A')
+		if cookie.Value[12] == c {
+			c = 'B'
+		}
+		tampered.Value = cookie.Value[:12] + string(c) + cookie.Value[13:]
+		if s3, _ := getSession(t, p, &tampered, false); s3 != nil {
+			t.Error("tampered cookie is accepted")
+		}
+
+		other := newCookieProvider(t, sessions.CookieKey{HashKey: hashKey2, BlockKey: key.BlockKey})
+		if s4, _ := getSession(t, other, cookie, false); s4 != nil {
+			t.Error("cookie of an unknown key is accepted")
+		}
+	}
+}
+
+func TestCookieKeyRotation(t *testing.T) {
+	old := sessions.CookieKey{HashKey: hashKey1}
+	p := newCookieProvider(t, old)
+	s, w := getSession(t, p, nil, true)
+	s.Set("user", "alice")
+	cookie := saveCookie(t, s, w)
+
+	current := sessions.CookieKey{HashKey: hashKey2}
+	rotated := newCookieProvider(t, current, old)
+	s2, w2 := getSession(t, rotated, cookie, false)
+	if s2 == nil {
+		t.Fatal("cookie of the old key is not accepted")
+	}
+	reissued := saveCookie(t, s2, w2)
+	if reissued == nil {
+		t.Fatal("cookie of the old key is not re-issued")
+	}
+
+	only := newCookieProvider(t, current)
+	if s3, _ := getSession(t, only, reissued, false); s3 == nil {
+		t.Error("re-issued cookie is not signed by the current key")
+	}
+
+	s4, w4 := getSession(t, rotated, reissued, false)
+	if c := saveCookie(t, s4, w4); c != nil {
+		t.Error("cookie of the current key is re-issued without changes")
+	}
+}
+
+func TestCookieTooLarge(t *testing.T) {
+	p := newCookieProvider(t, sessions.CookieKey{HashKey: hashKey1})
+	s, _ := getSession(t, p, nil, true)
+
+	if err := s.Set("big", strings.Repeat("x", 5000)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Save(); !errors.Is(err, sessions.ErrCookieTooLarge) {
+		t.Errorf("Save returns %v, want ErrCookieTooLarge", err)
+	}
+
+	s.Del("big")
+	if err := s.Save(); err != nil {
+		t.Errorf("Save after Del: %v", err)
+	}
+}
+
+func TestCookieInvalidate(t *testing.T) {
+	p := newCookieProvider(t, sessions.CookieKey{HashKey: hashKey1})
+	s, w := getSession(t, p, nil, true)
+	s.Set("user", "alice")
+	cookie := saveCookie(t, s, w)
+
+	s2, w2 := getSession(t, p, cookie, false)
+	if err := s2.Invalidate(); err != nil {
+		t.Fatal(err)
+	}
+	if c := responseCookie(w2, "gsessionid"); c == nil || c.MaxAge >= 0 {
+		t.Errorf("cookie = %v, want deleted", c)
+	}
+	if err := s2.Regenerate(); err == nil {
+		t.Error("Regenerate of an invalidated session returns no error")
+	}
+}
+
+func TestCookieTimeout(t *testing.T) {
+	p := newCookieProvider(t, sessions.CookieKey{HashKey: hashKey1})
+	s, w := getSession(t, p, nil, true)
+	s.SetTimeout(time.Minute, 0)
+	cookie := saveCookie(t, s, w)
+
+	if cookie.MaxAge <= 0 || cookie.MaxAge > 60 {
+		t.Errorf("cookie max age = %d, want the custom timeout", cookie.MaxAge)
+	}
+
+	s2, _ := getSession(t, p, cookie, false)
+	if d := time.Until(s2.ExpiresAt()); d <= 0 || d > time.Minute {
+		t.Errorf("session expires in %v, want the custom timeout", d)
+	}
+}
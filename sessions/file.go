@@ -0,0 +1,443 @@
+package sessions
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	lockFileName  = ".lock"
+	tmpFilePrefix = ".tmp-"
+	fileTimesLen  = 32
+	fileHeaderLen = fileTimesLen + 2
+	maxPrincipal  = 1<<16 - 1
+)
+
+var (
+	errInvalidId       = errors.New("invalid session id")
+	errInvalidFile     = errors.New("invalid session file")
+	errPrincipalLength = errors.New("session principal too long")
+)
+
+type FileProvider struct {
+	*Provider
+	Store *FileStore
+}
+
+func NewFileProvider(dir string, timeout time.Duration) (*FileProvider, error) {
+	store, err := NewFileStore(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileProvider{
+		Provider: NewProvider(store, timeout),
+		Store:    store,
+	}, nil
+}
+
+func (p *FileProvider) Close() error {
+	p.Provider.Close()
+	return p.Store.Close()
+}
+
+type FileStore struct {
+	Codec Codec
+
+	mutex   sync.RWMutex
+	rmutex  sync.Mutex
+	readers int
+	dir     string
+	lock    *os.File
+	metrics Metrics
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	lock, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		Codec: GobCodec{},
+		dir:   dir,
+		lock:  lock,
+	}, nil
+}
+
+func (s *FileStore) Close() error {
+	return s.lock.Close()
+}
+
+func (s *FileStore) Load(id string) (*Record, error) {
+	if !validId(id) {
+		return nil, nil
+	}
+
+	release, err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.read(id, time.Now())
+}
+
+func (s *FileStore) read(id string, now time.Time) (*Record, error) {
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r, values, err := decodeHeader(data)
+	if err != nil || r.Expired(now) {
+		return nil, nil
+	}
+
+	if r.Values, err = s.Codec.Decode(values); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *FileStore) Save(id string, r *Record) error {
+	if !validId(id) {
+		return errInvalidId
+	}
+
+	data, err := s.encode(r)
+	if err != nil {
+		return err
+	}
+
+	release, err := s.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.write(id, data, r.ExpiresAt())
+}
+
+func (s *FileStore) Update(id string, fn func(r *Record) error) (bool, error) {
+	if !validId(id) {
+		return false, nil
+	}
+
+	release, err := s.acquire(true)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	r, err := s.read(id, time.Now())
+	if err != nil || r == nil {
+		return false, err
+	}
+	if err := fn(r); err != nil {
+		return false, err
+	}
+
+	data, err := s.encode(r)
+	if err != nil {
+		return false, err
+	}
+	return true, s.write(id, data, r.ExpiresAt())
+}
+
+func (s *FileStore) encode(r *Record) ([]byte, error) {
+	if len(r.Principal) > maxPrincipal {
+		return nil, errPrincipalLength
+	}
+
+	values, err := s.Codec.Encode(r.Values)
+	if err != nil {
+		return nil, err
+	}
+	return append(encodeHeader(r), values...), nil
+}
+
+func (s *FileStore) write(id string, data []byte, expires time.Time) error {
+	tmp, err := os.CreateTemp(s.dir, tmpFilePrefix+id+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), time.Now(), expires); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(id))
+}
+
+func (s *FileStore) Delete(id string) (bool, error) {
+	if !validId(id) {
+		return false, nil
+	}
+
+	release, err := s.acquire(true)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	// the modification time of a session file is its expiry
+	fi, err := os.Stat(s.path(id))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return !time.Now().After(fi.ModTime()), nil
+}
+
+func (s *FileStore) Touch(id string, lastAccess time.Time) error {
+	if !validId(id) {
+		return nil
+	}
+
+	release, err := s.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	p := s.path(id)
+	f, err := os.OpenFile(p, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, fileTimesLen)
+	if _, err := io.ReadFull(f, header); err != nil {
+		f.Close()
+		return nil
+	}
+
+	r := decodeTimes(header)
+	if r.Expired(time.Now()) {
+		f.Close()
+		return nil
+	}
+
+	r.LastAccess = lastAccess
+	if _, err := f.WriteAt(encodeTimes(r), 0); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(p, time.Now(), r.ExpiresAt())
+}
+
+func (s *FileStore) GC() error {
+	release, err := s.acquire(true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(validId(name) || strings.HasPrefix(name, tmpFilePrefix)) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if now.After(fi.ModTime()) {
+			if os.Remove(s.path(name)) == nil && validId(name) {
+				s.metrics.Expired++
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *FileStore) Count() (int, error) {
+	ids, err := s.ids()
+	return len(ids), err
+}
+
+func (s *FileStore) Each(fn func(id string, r *Record) bool) error {
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		release, err := s.acquire(false)
+		if err != nil {
+			return err
+		}
+		r, err := s.read(id, now)
+		release()
+
+		if err != nil {
+			return err
+		}
+		if r != nil && !fn(id, r) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) Metrics() Metrics {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.metrics
+}
+
+func (s *FileStore) ids() ([]string, error) {
+	release, err := s.acquire(false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var ids []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !validId(name) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil || now.After(fi.ModTime()) {
+			continue
+		}
+		ids = append(ids, name)
+	}
+	return ids, nil
+}
+
+func encodeHeader(r *Record) []byte {
+	b := make([]byte, fileHeaderLen, fileHeaderLen+len(r.Principal))
+	copy(b, encodeTimes(r))
+	binary.BigEndian.PutUint16(b[fileTimesLen:], uint16(len(r.Principal)))
+	return append(b, r.Principal...)
+}
+
+func decodeHeader(b []byte) (*Record, []byte, error) {
+	if len(b) < fileHeaderLen {
+		return nil, nil, errInvalidFile
+	}
+
+	n := int(binary.BigEndian.Uint16(b[fileTimesLen:]))
+	if len(b) < fileHeaderLen+n {
+		return nil, nil, errInvalidFile
+	}
+
+	r := decodeTimes(b)
+	r.Principal = string(b[fileHeaderLen : fileHeaderLen+n])
+	return r, b[fileHeaderLen+n:], nil
+}
+
+func encodeTimes(r *Record) []byte {
+	b := make([]byte, fileTimesLen)
+	binary.BigEndian.PutUint64(b[0:], uint64(r.CreatedAt.UnixNano()))
+	binary.BigEndian.PutUint64(b[8:], uint64(r.LastAccess.UnixNano()))
+	binary.BigEndian.PutUint64(b[16:], uint64(r.Timeout))
+	binary.BigEndian.PutUint64(b[24:], uint64(r.Lifetime))
+	return b
+}
+
+func decodeTimes(b []byte) *Record {
+	return &Record{
+		CreatedAt:  time.Unix(0, int64(binary.BigEndian.Uint64(b[0:]))),
+		LastAccess: time.Unix(0, int64(binary.BigEndian.Uint64(b[8:]))),
+		Timeout:    time.Duration(binary.BigEndian.Uint64(b[16:])),
+		Lifetime:   time.Duration(binary.BigEndian.Uint64(b[24:])),
+	}
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *FileStore) acquire(exclusive bool) (func(), error) {
+	if exclusive {
+		s.mutex.Lock()
+		if err := lockFile(s.lock, true); err != nil {
+			s.mutex.Unlock()
+			return nil, err
+		}
+		return func() {
+			unlockFile(s.lock)
+			s.mutex.Unlock()
+		}, nil
+	}
+
+	s.mutex.RLock()
+	s.rmutex.Lock()
+	defer s.rmutex.Unlock()
+
+	// flock belongs to the open file, the shared lock is taken by the
+	// first reader and released by the last one.
+	if s.readers == 0 {
+		if err := lockFile(s.lock, false); err != nil {
+			s.mutex.RUnlock()
+			return nil, err
+		}
+	}
+	s.readers++
+
+	return func() {
+		s.rmutex.Lock()
+		s.readers--
+		if s.readers == 0 {
+			unlockFile(s.lock)
+		}
+		s.rmutex.Unlock()
+		s.mutex.RUnlock()
+	}, nil
+}
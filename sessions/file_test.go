@@ -0,0 +1,18 @@
+package sessions_test
+
+import (
+	"github.com/hujh/gmvc/sessions"
+	"github.com/hujh/gmvc/sessions/storetest"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	storetest.TestStore(t, func() sessions.Store {
+		s, err := sessions.NewFileStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
@@ -2,354 +2,309 @@ package sessions
 
 import (
 	"container/list"
-	"crypto/md5"
-	"crypto/rand"
-	"encoding/hex"
 	"errors"
-	"github.com/hujh/gmvc"
-	"io"
-	"net/http"
-	"runtime"
+	"fmt"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
-var (
-	defaultCookieName string        = "gsessionid"
-	defaultTimeout    time.Duration = 30 * time.Minute
+const (
+	memoryOverhead = 256
 )
 
-type Options struct {
-	CookieName     string
-	CookiePath     string
-	CookieDomain   string
-	CookieMaxAge   int
-	CookieSecure   bool
-	CookieHttpOnly bool
-}
+var (
+	ErrTooManyKeys     = errors.New("too many session keys")
+	ErrSessionTooLarge = errors.New("session too large")
+)
 
 type MemoryProvider struct {
-	*Options
-	storage *memoryStorage
+	*Provider
+	Store *MemoryStore
 }
 
 func NewMemoryProvider(timeout time.Duration) *MemoryProvider {
-	options := &Options{CookieName: defaultCookieName, CookieHttpOnly: true}
-	storage := newMemoryStorage(timeout)
-
-	p := &MemoryProvider{
-		Options: options,
-		storage: storage,
+	store := NewMemoryStore()
+	return &MemoryProvider{
+		Provider: NewProvider(store, timeout),
+		Store:    store,
 	}
-
-	runtime.SetFinalizer(p, func(x *MemoryProvider) { x.storage.destory() })
-	return p
 }
 
-func (p *MemoryProvider) GetSession(w http.ResponseWriter, r *http.Request, create bool) (s gmvc.Session, err error) {
-	var values *memoryValues
-
-	id := p.getCookieId(r)
-	if id == "" {
-		if create {
-			for i := 0; i < 5; i++ {
-				id, err = p.generateId()
-				if err != nil {
-					return
-				}
-				v, ok := p.storage.alloc(id)
-				if ok {
-					values = v
-					break
-				}
-			}
-			if values == nil {
-				return nil, errors.New("fail to create session")
-			}
-			p.setCookieId(w, id, true)
-		} else {
-			return
-		}
-	} else {
-		values = p.storage.touch(id)
-	}
+type MemoryStore struct {
+	MaxSessions int
+	MaxBytes    int64
+	MaxKeys     int
+
+	mutex      sync.Mutex
+	elems      map[string]*list.Element
+	order      *list.List
+	principals map[string]map[string]struct{}
+	metrics    Metrics
+	size       int64
+}
 
-	s = &memorySession{
-		provider: p,
-		values:   values,
-		id:       id,
-		w:        w,
-		valid:    true,
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		elems:      make(map[string]*list.Element),
+		order:      list.New(),
+		principals: make(map[string]map[string]struct{}),
 	}
-
-	p.storage.gc()
-
-	return
 }
 
-func (p *MemoryProvider) deleteSession(w http.ResponseWriter, id string) {
-	p.storage.delete(id)
-	p.setCookieId(w, id, false)
-	return
-}
+func (s *MemoryStore) Load(id string) (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-func (p *MemoryProvider) generateId() (string, error) {
-	b := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, b); err != nil {
-		return "", err
+	e := s.elems[id]
+	if e == nil {
+		return nil, nil
 	}
 
-	hash := md5.New()
-	if _, err := hash.Write(b); err != nil {
-		return "", err
+	v := e.Value.(*memoryValues)
+	if v.record.Expired(time.Now()) {
+		s.expire(e)
+		return nil, nil
 	}
 
-	b = hash.Sum(nil)
-	return hex.EncodeToString(b), nil
+	s.order.MoveToFront(e)
+	return v.record.Copy(), nil
 }
 
-func (p *MemoryProvider) getCookieId(r *http.Request) string {
-	for _, c := range r.Cookies() {
-		if c.Name == p.CookieName {
-			return c.Value
-		}
+func (s *MemoryStore) Save(id string, r *Record) error {
+	size, err := s.check(r)
+	if err != nil {
+		return err
 	}
-	return ""
-}
 
-func (p *MemoryProvider) setCookieId(w http.ResponseWriter, id string, persist bool) {
-	cookie := &http.Cookie{
-		Name:     p.CookieName,
-		Domain:   p.CookieDomain,
-		Path:     p.CookiePath,
-		Secure:   p.CookieSecure,
-		HttpOnly: p.CookieHttpOnly,
-		Value:    id,
-	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	if persist {
-		cookie.MaxAge = p.CookieMaxAge
+	e := s.elems[id]
+	if e != nil {
+		s.replace(e, r, size)
 	} else {
-		cookie.MaxAge = -1
+		v := &memoryValues{
+			id:     id,
+			record: r.Copy(),
+			size:   size,
+		}
+		e = s.order.PushFront(v)
+		s.elems[id] = e
+		s.size += size
+		s.index(v)
 	}
 
-	w.Header().Add("Cache-Control", "private")
-	http.SetCookie(w, cookie)
-}
-
-type memorySession struct {
-	w        http.ResponseWriter
-	provider *MemoryProvider
-	values   *memoryValues
-	id       string
-	valid    bool
-}
-
-func (s *memorySession) Id() string {
-	return s.id
+	s.evict(e)
+	return nil
 }
 
-func (s *memorySession) Valid() bool {
-	return s.valid
-}
+func (s *MemoryStore) Update(id string, fn func(r *Record) error) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-func (s *memorySession) Invalidate() error {
-	if s.valid {
-		s.valid = false
-		s.provider.deleteSession(s.w, s.id)
+	e := s.elems[id]
+	if e == nil {
+		return false, nil
+	}
+	if e.Value.(*memoryValues).record.Expired(time.Now()) {
+		s.expire(e)
+		return false, nil
 	}
-	return nil
-}
-
-func (s *memorySession) Save() error {
-	return nil
-}
 
-func (s *memorySession) Set(key string, value interface{}) error {
-	return s.values.set(key, value)
-}
+	r := e.Value.(*memoryValues).record.Copy()
+	if err := fn(r); err != nil {
+		return false, err
+	}
+	size, err := s.check(r)
+	if err != nil {
+		return false, err
+	}
 
-func (s *memorySession) Get(key string) (interface{}, error) {
-	return s.values.get(key)
+	s.replace(e, r, size)
+	s.evict(e)
+	return true, nil
 }
 
-func (s *memorySession) Del(key string) error {
-	return s.values.del(key)
+func (s *MemoryStore) Validate(r *Record) error {
+	_, err := s.check(r)
+	return err
 }
 
-type memoryStorage struct {
-	mutex   sync.Mutex
-	elems   map[string]*list.Element
-	order   *list.List
-	timeout time.Duration
-	maxidle int
-	sem     int32
-}
+func (s *MemoryStore) Delete(id string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-func newMemoryStorage(timeout time.Duration) *memoryStorage {
-	if timeout <= 0 {
-		timeout = defaultTimeout
+	e := s.elems[id]
+	if e == nil {
+		return false, nil
 	}
-
-	return &memoryStorage{
-		elems:   make(map[string]*list.Element),
-		order:   list.New(),
-		timeout: timeout,
-		maxidle: 60,
+	if e.Value.(*memoryValues).record.Expired(time.Now()) {
+		s.expire(e)
+		return false, nil
 	}
+	s.remove(e)
+	return true, nil
 }
 
-func (s *memoryStorage) alloc(id string) (v *memoryValues, ok bool) {
+func (s *MemoryStore) Touch(id string, lastAccess time.Time) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	e := s.elems[id]
 	if e == nil {
-		v = s.make(id)
-		e := s.order.PushFront(v)
-		s.elems[id] = e
-		ok = true
-		return
+		return nil
+	}
+
+	r := e.Value.(*memoryValues).record
+	if r.Expired(time.Now()) {
+		s.expire(e)
+		return nil
 	}
 
-	return nil, false
+	r.LastAccess = lastAccess
+	s.order.MoveToFront(e)
+	return nil
 }
 
-func (s *memoryStorage) touch(id string) (v *memoryValues) {
+func (s *MemoryStore) GC() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	e := s.elems[id]
-	if e == nil {
-		v = s.make(id)
-		e := s.order.PushFront(v)
-		s.elems[id] = e
-	} else {
-		v = e.Value.(*memoryValues)
-		v.utime = time.Now()
-		s.order.MoveToFront(e)
+	now := time.Now()
+	for e := s.order.Back(); e != nil; {
+		olde := e
+		e = e.Prev()
+		if olde.Value.(*memoryValues).record.Expired(now) {
+			s.expire(olde)
+		}
 	}
-
-	return
+	return nil
 }
 
-func (s *memoryStorage) make(id string) *memoryValues {
-	return &memoryValues{
-		id:     id,
-		utime:  time.Now(),
-		values: make(map[string]interface{}),
-	}
+func (s *MemoryStore) Count() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.order.Len(), nil
 }
 
-func (s *memoryStorage) delete(id string) {
+func (s *MemoryStore) Each(fn func(id string, r *Record) bool) error {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	now := time.Now()
+	values := make([]memoryValues, 0, s.order.Len())
+	for e := s.order.Front(); e != nil; e = e.Next() {
+		v := e.Value.(*memoryValues)
+		if !v.record.Expired(now) {
+			values = append(values, memoryValues{id: v.id, record: v.record.Copy()})
+		}
+	}
+	s.mutex.Unlock()
 
-	if e := s.elems[id]; e != nil {
-		delete(s.elems, id)
-		s.order.Remove(e)
+	for _, v := range values {
+		if !fn(v.id, v.record) {
+			break
+		}
 	}
+	return nil
 }
 
-func (s *memoryStorage) gc() {
-	if atomic.CompareAndSwapInt32(&s.sem, 0, 1) {
-		go func() {
-			ticker := time.NewTicker(time.Second)
-			defer ticker.Stop()
-
-			idle := 0
-			timeout := s.timeout
-			for {
-				if atomic.LoadInt32(&s.sem) == -1 {
-					return
-				}
-
-				<-ticker.C
-				performed := false
-
-				for {
-					if ok, count := s.trim(timeout, 4096); ok {
-						performed = true
-						if count == 0 {
-							break
-						}
-					} else {
-						break
-					}
-				}
-
-				if performed {
-					idle = 0
-				} else {
-					idle++
-					if idle >= s.maxidle {
-						break
-					}
-				}
-			}
-			atomic.CompareAndSwapInt32(&s.sem, 1, 0)
-		}()
+func (s *MemoryStore) FindByPrincipal(principal string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ids := make([]string, 0, len(s.principals[principal]))
+	for id := range s.principals[principal] {
+		ids = append(ids, id)
 	}
+	return ids, nil
 }
 
-func (s *memoryStorage) trim(timeout time.Duration, limit int) (bool, int) {
+func (s *MemoryStore) Metrics() Metrics {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	return s.metrics
+}
 
-	if len(s.elems) == 0 {
-		return false, 0
+func (s *MemoryStore) check(r *Record) (int64, error) {
+	if s.MaxKeys > 0 && len(r.Values) > s.MaxKeys {
+		return 0, fmt.Errorf("%w: %d keys, limit %d", ErrTooManyKeys, len(r.Values), s.MaxKeys)
 	}
 
-	count := 0
-	now := time.Now()
-	for e := s.order.Back(); e != nil; {
-		values := e.Value.(*memoryValues)
-		if now.Sub(values.utime) > timeout {
-			if count > limit {
-				break
-			}
-			olde := e
-			e = e.Prev()
-			s.order.Remove(olde)
-			delete(s.elems, values.id)
-			count++
+	size := recordSize(r)
+	if s.MaxBytes > 0 && size > s.MaxBytes {
+		return 0, fmt.Errorf("%w: about %d bytes, limit %d", ErrSessionTooLarge, size, s.MaxBytes)
+	}
+	return size, nil
+}
 
+func (s *MemoryStore) replace(e *list.Element, r *Record, size int64) {
+	v := e.Value.(*memoryValues)
+	s.unindex(v)
+	s.size += size - v.size
+	v.record = r.Copy()
+	v.size = size
+	s.index(v)
+	s.order.MoveToFront(e)
+}
+
+func (s *MemoryStore) evict(keep *list.Element) {
+	now := time.Now()
+	for s.overLimit() {
+		e := s.order.Back()
+		if e == nil || e == keep {
+			return
+		}
+		if e.Value.(*memoryValues).record.Expired(now) {
+			s.expire(e)
 		} else {
-			break
+			s.remove(e)
+			s.metrics.Evicted++
 		}
 	}
+}
 
-	return true, count
+func (s *MemoryStore) overLimit() bool {
+	return (s.MaxSessions > 0 && s.order.Len() > s.MaxSessions) ||
+		(s.MaxBytes > 0 && s.size > s.MaxBytes)
 }
 
-func (s *memoryStorage) destory() {
-	atomic.StoreInt32(&s.sem, -1)
+func (s *MemoryStore) expire(e *list.Element) {
+	s.remove(e)
+	s.metrics.Expired++
 }
 
-type memoryValues struct {
-	mutex  sync.RWMutex
-	id     string
-	utime  time.Time
-	values map[string]interface{}
+func (s *MemoryStore) remove(e *list.Element) {
+	v := e.Value.(*memoryValues)
+	s.order.Remove(e)
+	delete(s.elems, v.id)
+	s.size -= v.size
+	s.unindex(v)
 }
 
-func (s *memoryValues) set(key string, value interface{}) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.values[key] = value
-	return nil
+func (s *MemoryStore) index(v *memoryValues) {
+	p := v.record.Principal
+	if p == "" {
+		return
+	}
+	ids := s.principals[p]
+	if ids == nil {
+		ids = make(map[string]struct{})
+		s.principals[p] = ids
+	}
+	ids[v.id] = struct{}{}
 }
 
-func (s *memoryValues) get(key string) (interface{}, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return s.values[key], nil
+func (s *MemoryStore) unindex(v *memoryValues) {
+	p := v.record.Principal
+	if ids := s.principals[p]; ids != nil {
+		delete(ids, v.id)
+		if len(ids) == 0 {
+			delete(s.principals, p)
+		}
+	}
 }
 
-func (s *memoryValues) del(key string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	delete(s.values, key)
-	return nil
+type memoryValues struct {
+	id     string
+	record *Record
+	size   int64
 }
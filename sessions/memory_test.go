@@ -0,0 +1,44 @@
+package sessions_test
+
+import (
+	"github.com/hujh/gmvc/sessions"
+	"github.com/hujh/gmvc/sessions/storetest"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.TestStore(t, func() sessions.Store {
+		return sessions.NewMemoryStore()
+	})
+}
+
+func TestMemoryEviction(t *testing.T) {
+	p := sessions.NewMemoryProvider(time.Hour)
+	defer p.Close()
+	p.Store.MaxSessions = 1
+
+	evicted := newSession(t, p, map[string]interface{}{"user": "alice"})
+	inflight, w := getSession(t, p, evicted, false)
+	kept := newSession(t, p, map[string]interface{}{"user": "bob"})
+
+	inflight.Set("cart", 1)
+	if err := inflight.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if inflight.Valid() {
+		t.Error("evicted session is valid after Save")
+	}
+	if c := responseCookie(w, "gsessionid"); c == nil || c.MaxAge >= 0 {
+		t.Errorf("cookie = %v, want deleted", c)
+	}
+	if s, _ := getSession(t, p, evicted, false); s != nil {
+		t.Error("Save brings the evicted session back")
+	}
+	if s, _ := getSession(t, p, kept, false); s == nil {
+		t.Error("Save of evicted session evicts the live one")
+	}
+	if m := p.Metrics(); m.Evicted != 1 {
+		t.Errorf("evicted = %d, want 1", m.Evicted)
+	}
+}
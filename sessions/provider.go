@@ -0,0 +1,564 @@
+package sessions
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/hujh/gmvc"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	defaultCookieName string        = "gsessionid"
+	defaultTimeout    time.Duration = 30 * time.Minute
+	maxGCPeriod       time.Duration = time.Minute
+)
+
+var (
+	errInvalidated = errors.New("session is invalidated")
+	errRevoked     = errors.New("session is revoked")
+)
+
+type Record struct {
+	Values     map[string]interface{}
+	CreatedAt  time.Time
+	LastAccess time.Time
+	Timeout    time.Duration
+	Lifetime   time.Duration
+	Principal  string
+}
+
+func (r *Record) ExpiresAt() time.Time {
+	t := r.LastAccess.Add(r.Timeout)
+	if r.Lifetime > 0 {
+		if end := r.CreatedAt.Add(r.Lifetime); end.Before(t) {
+			t = end
+		}
+	}
+	return t
+}
+
+func (r *Record) Expired(now time.Time) bool {
+	return now.After(r.ExpiresAt())
+}
+
+func (r *Record) Copy() *Record {
+	c := *r
+	c.Values = make(map[string]interface{}, len(r.Values))
+	for k, v := range r.Values {
+		c.Values[k] = v
+	}
+	return &c
+}
+
+type Store interface {
+	Load(id string) (*Record, error)
+	Save(id string, r *Record) error
+	Update(id string, fn func(r *Record) error) (bool, error)
+	Delete(id string) (bool, error)
+	Touch(id string, lastAccess time.Time) error
+	GC() error
+}
+
+type ValidatingStore interface {
+	Store
+	Validate(r *Record) error
+}
+
+type Options struct {
+	CookieName     string
+	CookiePath     string
+	CookieDomain   string
+	CookieMaxAge   int
+	CookieSecure   bool
+	CookieHttpOnly bool
+}
+
+func (o *Options) getCookie(r *http.Request) string {
+	for _, c := range r.Cookies() {
+		if c.Name == o.CookieName {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+func (o *Options) setCookie(w http.ResponseWriter, value string, maxAge int) {
+	cookie := &http.Cookie{
+		Name:     o.CookieName,
+		Domain:   o.CookieDomain,
+		Path:     o.CookiePath,
+		Secure:   o.CookieSecure,
+		HttpOnly: o.CookieHttpOnly,
+		Value:    value,
+		MaxAge:   maxAge,
+	}
+
+	h := w.Header()
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", "private")
+	}
+
+	prefix := cookie.Name + "="
+	cookies := h["Set-Cookie"][:0]
+	for _, c := range h["Set-Cookie"] {
+		if !strings.HasPrefix(c, prefix) {
+			cookies = append(cookies, c)
+		}
+	}
+	h["Set-Cookie"] = append(cookies, cookie.String())
+}
+
+type Provider struct {
+	*Options
+	MaxLifetime  time.Duration
+	PrincipalKey string
+
+	store   Store
+	timeout time.Duration
+	stop    chan struct{}
+	once    sync.Once
+	mutex   sync.Mutex
+	metrics Metrics
+}
+
+func NewProvider(store Store, timeout time.Duration) *Provider {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	p := &Provider{
+		Options: &Options{CookieName: defaultCookieName, CookieHttpOnly: true},
+		store:   store,
+		timeout: timeout,
+		stop:    make(chan struct{}),
+	}
+
+	period := timeout / 2
+	if period > maxGCPeriod {
+		period = maxGCPeriod
+	}
+	go gc(store, period, p.stop)
+
+	runtime.SetFinalizer(p, func(x *Provider) { x.Close() })
+	return p
+}
+
+func (p *Provider) Close() error {
+	p.once.Do(func() { close(p.stop) })
+	return nil
+}
+
+func (p *Provider) GetSession(w http.ResponseWriter, r *http.Request, create bool) (gmvc.Session, error) {
+	s := &session{
+		provider: p,
+		w:        w,
+		valid:    true,
+	}
+
+	if id := p.getCookie(r); validId(id) {
+		record, err := p.store.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			s.id = id
+			s.record = record
+			s.stored = true
+			return s, nil
+		}
+	}
+
+	if !create {
+		return nil, nil
+	}
+	if err := s.create(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *Provider) newRecord() *Record {
+	now := time.Now()
+	return &Record{
+		Values:     make(map[string]interface{}),
+		CreatedAt:  now,
+		LastAccess: now,
+		Timeout:    p.timeout,
+		Lifetime:   p.MaxLifetime,
+	}
+}
+
+func (p *Provider) validate(r *Record) error {
+	if s, ok := p.store.(ValidatingStore); ok {
+		return s.Validate(r)
+	}
+	return nil
+}
+
+func (p *Provider) customTimeout(r *Record) bool {
+	return r.Timeout != p.timeout || r.Lifetime != p.MaxLifetime
+}
+
+func (p *Provider) cookieMaxAge(r *Record) int {
+	if !p.customTimeout(r) {
+		return p.CookieMaxAge
+	}
+	return maxAge(r.ExpiresAt())
+}
+
+func maxAge(expires time.Time) int {
+	if n := int(time.Until(expires) / time.Second); n > 0 {
+		return n
+	}
+	return -1
+}
+
+func gc(store Store, period time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			store.GC()
+		}
+	}
+}
+
+func generateId() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+
+	hash := md5.New()
+	if _, err := hash.Write(b); err != nil {
+		return "", err
+	}
+
+	b = hash.Sum(nil)
+	return hex.EncodeToString(b), nil
+}
+
+func validId(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+type session struct {
+	mutex    sync.Mutex
+	w        http.ResponseWriter
+	provider *Provider
+	id       string
+	record   *Record
+	valid    bool
+	stored   bool
+	dirty    bool
+	touched  bool
+	changed  map[string]struct{}
+	timeout  bool
+}
+
+func (s *session) Id() string {
+	return s.id
+}
+
+func (s *session) Valid() bool {
+	return s.valid
+}
+
+func (s *session) Invalidate() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.valid {
+		s.valid = false
+		s.provider.setCookie(s.w, s.id, -1)
+		_, err := s.provider.store.Delete(s.id)
+		return err
+	}
+	return nil
+}
+
+func (s *session) Regenerate() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid {
+		return errInvalidated
+	}
+
+	record := s.record
+	if s.stored {
+		r, err := s.provider.store.Load(s.id)
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			s.drop()
+			return errRevoked
+		}
+		record = r
+	}
+	s.merge(record)
+
+	id, err := generateId()
+	if err != nil {
+		return err
+	}
+	if err := s.provider.store.Save(id, record); err != nil {
+		return err
+	}
+	if s.stored {
+		// Delete reports a revoke since the Load, the new id must not
+		// outlive the old one
+		deleted, err := s.provider.store.Delete(s.id)
+		if err != nil || !deleted {
+			s.provider.store.Delete(id)
+			if err != nil {
+				return err
+			}
+			s.drop()
+			return errRevoked
+		}
+	}
+
+	s.id = id
+	s.record = record
+	s.provider.setCookie(s.w, id, s.provider.cookieMaxAge(s.record))
+	s.stored = true
+	s.dirty = false
+	s.touched = true
+	s.changed = nil
+	s.timeout = false
+	return nil
+}
+
+func (s *session) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid {
+		return nil
+	}
+	if !s.dirty {
+		return s.touch()
+	}
+
+	if s.stored {
+		var merged *Record
+		ok, err := s.provider.store.Update(s.id, func(r *Record) error {
+			s.merge(r)
+			merged = r.Copy()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.drop()
+			return nil
+		}
+		s.record = merged
+	} else {
+		s.merge(s.record)
+		if err := s.provider.store.Save(s.id, s.record); err != nil {
+			return err
+		}
+	}
+
+	s.changed = nil
+	s.timeout = false
+	if s.provider.customTimeout(s.record) {
+		s.provider.setCookie(s.w, s.id, s.provider.cookieMaxAge(s.record))
+	}
+	s.stored = true
+	s.dirty = false
+	s.touched = true
+	return nil
+}
+
+// merge applies the changes of this request to r, the values changed
+// by other requests in the meantime are kept.
+func (s *session) merge(r *Record) {
+	for key := range s.changed {
+		if v, ok := s.record.Values[key]; ok {
+			r.Values[key] = v
+		} else {
+			delete(r.Values, key)
+		}
+	}
+	if s.timeout {
+		r.Timeout = s.record.Timeout
+		r.Lifetime = s.record.Lifetime
+	}
+	r.LastAccess = time.Now()
+	r.Principal = s.provider.principal(r.Values)
+}
+
+func (s *session) drop() {
+	s.valid = false
+	s.provider.setCookie(s.w, s.id, -1)
+}
+
+func (s *session) change(key string) {
+	if s.changed == nil {
+		s.changed = make(map[string]struct{})
+	}
+	s.changed[key] = struct{}{}
+	s.dirty = true
+}
+
+func (s *session) Touch() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid {
+		return nil
+	}
+	s.touched = false
+	return s.touch()
+}
+
+func (s *session) touch() error {
+	if s.touched {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.provider.store.Touch(s.id, now); err != nil {
+		return err
+	}
+	s.record.LastAccess = now
+	if s.provider.customTimeout(s.record) {
+		s.provider.setCookie(s.w, s.id, s.provider.cookieMaxAge(s.record))
+	}
+	s.touched = true
+	return nil
+}
+
+func (s *session) create() error {
+	id, err := generateId()
+	if err != nil {
+		return err
+	}
+
+	s.id = id
+	s.record = s.provider.newRecord()
+	s.dirty = true
+	s.provider.setCookie(s.w, id, s.provider.CookieMaxAge)
+
+	s.provider.mutex.Lock()
+	s.provider.metrics.Created++
+	s.provider.mutex.Unlock()
+	return nil
+}
+
+func (s *session) CreatedAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.CreatedAt
+}
+
+func (s *session) LastAccess() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.LastAccess
+}
+
+func (s *session) ExpiresAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.ExpiresAt()
+}
+
+func (s *session) SetTimeout(timeout time.Duration, lifetime time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.valid {
+		return errInvalidated
+	}
+
+	if timeout <= 0 {
+		timeout = s.provider.timeout
+	}
+	switch {
+	case lifetime == 0:
+		lifetime = s.provider.MaxLifetime
+	case lifetime < 0:
+		lifetime = 0
+	}
+	s.record.Timeout = timeout
+	s.record.Lifetime = lifetime
+	s.record.LastAccess = time.Now()
+	s.timeout = true
+	s.dirty = true
+
+	s.provider.setCookie(s.w, s.id, s.provider.cookieMaxAge(s.record))
+	return nil
+}
+
+func (s *session) Keys() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return sortedKeys(s.record.Values), nil
+}
+
+func (s *session) Set(key string, value interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values := s.record.Values
+	old, ok := values[key]
+	values[key] = value
+	if err := s.provider.validate(s.record); err != nil {
+		if ok {
+			values[key] = old
+		} else {
+			delete(values, key)
+		}
+		return err
+	}
+	s.change(key)
+	return nil
+}
+
+func (s *session) Get(key string) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.record.Values[key], nil
+}
+
+func (s *session) Del(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.record.Values[key]; ok {
+		delete(s.record.Values, key)
+		s.change(key)
+	}
+	return nil
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,247 @@
+package sessions_test
+
+import (
+	"github.com/hujh/gmvc"
+	"github.com/hujh/gmvc/sessions"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newSession(t *testing.T, p gmvc.SessionProvider, values map[string]interface{}) *http.Cookie {
+	t.Helper()
+	s, w := getSession(t, p, nil, true)
+	for k, v := range values {
+		if err := s.Set(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cookie := saveCookie(t, s, w)
+	if cookie == nil {
+		t.Fatal("no session cookie")
+	}
+	return cookie
+}
+
+func newProviders(t *testing.T) map[string]*sessions.Provider {
+	t.Helper()
+	mp := sessions.NewMemoryProvider(time.Hour)
+	fp, err := sessions.NewFileProvider(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		mp.Close()
+		fp.Close()
+	})
+	return map[string]*sessions.Provider{"memory": mp.Provider, "file": fp.Provider}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	for name, p := range newProviders(t) {
+		t.Run(name, func(t *testing.T) { testConcurrentRequests(t, p) })
+	}
+}
+
+func testConcurrentRequests(t *testing.T, p *sessions.Provider) {
+	cookie := newSession(t, p, map[string]interface{}{"cart": 1, "theme": "dark", "user": "alice"})
+
+	s1, _ := getSession(t, p, cookie, false)
+	s2, _ := getSession(t, p, cookie, false)
+	s1.Set("cart", 2)
+	s1.Del("theme")
+	s2.Set("lang", "en")
+	s2.Set("user", "bob")
+	s1.Set("user", "carol")
+	if err := s1.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := getSession(t, p, cookie, false)
+	want := map[string]interface{}{"cart": 2, "lang": "en", "user": "bob"}
+	keys, _ := s.Keys()
+	if len(keys) != len(want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	for k, v := range want {
+		if got, _ := s.Get(k); got != v {
+			t.Errorf("%s = %v, want %v", k, got, v)
+		}
+	}
+	if got, _ := s2.Get("cart"); got != 2 {
+		t.Errorf("saved session has cart %v, want the merged value 2", got)
+	}
+}
+
+func TestSlidingExpiry(t *testing.T) {
+	for name, p := range newProviders(t) {
+		t.Run(name, func(t *testing.T) { testSlidingExpiry(t, p) })
+	}
+}
+
+func testSlidingExpiry(t *testing.T, p *sessions.Provider) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		lifetime time.Duration
+		reissued bool
+	}{
+		{"default", 0, 0, false},
+		{"custom timeout", time.Minute, -1, true},
+		{"custom lifetime", 0, time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		s, w := getSession(t, p, nil, true)
+		s.SetTimeout(tt.timeout, tt.lifetime)
+		cookie := saveCookie(t, s, w)
+		expires := s.ExpiresAt()
+
+		s2, w2 := getSession(t, p, cookie, false)
+		if s2 == nil {
+			t.Fatalf("%s: session not found", tt.name)
+		}
+		c := saveCookie(t, s2, w2)
+		if (c != nil) != tt.reissued {
+			t.Errorf("%s: cookie = %v, want re-issued %v", tt.name, c, tt.reissued)
+		}
+		if c != nil && (c.MaxAge <= 0 || c.MaxAge > 60) {
+			t.Errorf("%s: cookie max age = %d, want the custom timeout", tt.name, c.MaxAge)
+		}
+		if tt.lifetime <= 0 && !s2.ExpiresAt().After(expires) {
+			t.Errorf("%s: expiry %v doesn't slide from %v", tt.name, s2.ExpiresAt(), expires)
+		}
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	for name, p := range newProviders(t) {
+		t.Run(name, func(t *testing.T) { testRevoke(t, p) })
+	}
+}
+
+func testRevoke(t *testing.T, p *sessions.Provider) {
+	cookie := newSession(t, p, map[string]interface{}{"user": "alice"})
+	other := newSession(t, p, map[string]interface{}{"user": "bob"})
+
+	tests := []struct {
+		name    string
+		id      string
+		revoked uint64
+	}{
+		{"stored", cookie.Value, 1},
+		{"again", cookie.Value, 1},
+		{"unknown", "0123456789abcdef0123456789abcdef", 1},
+		{"invalid", "x", 1},
+	}
+
+	inflight, w := getSession(t, p, cookie, false)
+	for _, tt := range tests {
+		if err := p.Revoke(tt.id); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if m := p.Metrics(); m.Revoked != tt.revoked {
+			t.Errorf("%s: revoked = %d, want %d", tt.name, m.Revoked, tt.revoked)
+		}
+	}
+
+	inflight.Set("cart", 1)
+	if err := inflight.Save(); err != nil {
+		t.Fatalf("Save of revoked session: %v", err)
+	}
+	if inflight.Valid() {
+		t.Error("revoked session is valid after Save")
+	}
+	if c := responseCookie(w, "gsessionid"); c == nil || c.MaxAge >= 0 {
+		t.Errorf("cookie = %v, want deleted", c)
+	}
+	if s, _ := getSession(t, p, cookie, false); s != nil {
+		t.Error("Save brings the revoked session back")
+	}
+	if s, _ := getSession(t, p, other, false); s == nil {
+		t.Error("Revoke removes other session")
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	for name, p := range newProviders(t) {
+		t.Run(name, func(t *testing.T) { testRegenerate(t, p) })
+	}
+}
+
+func testRegenerate(t *testing.T, p *sessions.Provider) {
+	cookie := newSession(t, p, map[string]interface{}{"cart": 1})
+
+	s, w := getSession(t, p, cookie, false)
+	other, _ := getSession(t, p, cookie, false)
+	other.Set("theme", "dark")
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Set("user", "alice")
+	if err := s.Regenerate(); err != nil {
+		t.Fatal(err)
+	}
+	c := responseCookie(w, "gsessionid")
+	if c == nil || c.Value == cookie.Value || c.Value != s.Id() {
+		t.Fatalf("cookie = %v, want the new id %s", c, s.Id())
+	}
+	if old, _ := getSession(t, p, cookie, false); old != nil {
+		t.Error("old id is still stored")
+	}
+
+	s2, _ := getSession(t, p, c, false)
+	if s2 == nil {
+		t.Fatal("new id is not stored")
+	}
+	for k, v := range map[string]interface{}{"cart": 1, "theme": "dark", "user": "alice"} {
+		if got, _ := s2.Get(k); got != v {
+			t.Errorf("%s = %v, want %v", k, got, v)
+		}
+	}
+
+	revoked, _ := getSession(t, p, c, false)
+	if err := p.Revoke(c.Value); err != nil {
+		t.Fatal(err)
+	}
+	if err := revoked.Regenerate(); err == nil {
+		t.Error("Regenerate of revoked session returns no error")
+	}
+	if n, err := p.Count(); err != nil || n != 0 {
+		t.Errorf("count = %d, %v after Regenerate of revoked session, want 0", n, err)
+	}
+}
+
+// racingStore revokes a session right after it is loaded.
+type racingStore struct {
+	*sessions.MemoryStore
+	revoke bool
+}
+
+func (s *racingStore) Load(id string) (*sessions.Record, error) {
+	r, err := s.MemoryStore.Load(id)
+	if s.revoke {
+		s.MemoryStore.Delete(id)
+	}
+	return r, err
+}
+
+func TestRegenerateRevokeRace(t *testing.T) {
+	store := &racingStore{MemoryStore: sessions.NewMemoryStore()}
+	p := sessions.NewProvider(store, time.Hour)
+	defer p.Close()
+
+	cookie := newSession(t, p, map[string]interface{}{"user": "alice"})
+	s, _ := getSession(t, p, cookie, false)
+	store.revoke = true
+	if err := s.Regenerate(); err == nil {
+		t.Error("Regenerate of session revoked meanwhile returns no error")
+	}
+	if n, _ := store.Count(); n != 0 {
+		t.Errorf("count = %d, want the new id removed", n)
+	}
+}
@@ -0,0 +1,66 @@
+package sessions
+
+import (
+	"reflect"
+)
+
+const (
+	maxSizeDepth = 8
+)
+
+func recordSize(r *Record) int64 {
+	n := int64(memoryOverhead + len(r.Principal))
+	for k, v := range r.Values {
+		n += int64(len(k)) + 16 + valueSize(reflect.ValueOf(v), 0)
+	}
+	return n
+}
+
+func valueSize(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 16
+	}
+	if depth > maxSizeDepth {
+		return int64(v.Type().Size())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return 16 + int64(v.Len())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return 24 + int64(v.Cap())
+		}
+		n := int64(24)
+		for i := 0; i < v.Len(); i++ {
+			n += valueSize(v.Index(i), depth+1)
+		}
+		return n
+	case reflect.Array:
+		n := int64(0)
+		for i := 0; i < v.Len(); i++ {
+			n += valueSize(v.Index(i), depth+1)
+		}
+		return n
+	case reflect.Map:
+		n := int64(48)
+		iter := v.MapRange()
+		for iter.Next() {
+			n += valueSize(iter.Key(), depth+1) + valueSize(iter.Value(), depth+1)
+		}
+		return n
+	case reflect.Struct:
+		n := int64(0)
+		for i := 0; i < v.NumField(); i++ {
+			n += valueSize(v.Field(i), depth+1)
+		}
+		return n
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 8
+		}
+		return 8 + valueSize(v.Elem(), depth+1)
+	}
+
+	return int64(v.Type().Size())
+}
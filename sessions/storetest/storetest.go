@@ -0,0 +1,469 @@
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hujh/gmvc/sessions"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	id1 = "0123456789abcdef0123456789abcdef"
+	id2 = "fedcba9876543210fedcba9876543210"
+)
+
+func TestStore(t *testing.T, newStore func() sessions.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s sessions.Store)
+	}{
+		{"LoadMissing", testLoadMissing},
+		{"SaveLoad", testSaveLoad},
+		{"Record", testRecord},
+		{"Overwrite", testOverwrite},
+		{"Update", testUpdate},
+		{"LoadCopy", testLoadCopy},
+		{"Delete", testDelete},
+		{"Expire", testExpire},
+		{"Lifetime", testLifetime},
+		{"Touch", testTouch},
+		{"GC", testGC},
+		{"Concurrent", testConcurrent},
+		{"ConcurrentUpdate", testConcurrentUpdate},
+		{"Enumerate", testEnumerate},
+		{"Principal", testPrincipal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore())
+		})
+	}
+}
+
+func record(values map[string]interface{}, timeout time.Duration) *sessions.Record {
+	now := time.Now()
+	return &sessions.Record{
+		Values:     values,
+		CreatedAt:  now,
+		LastAccess: now,
+		Timeout:    timeout,
+	}
+}
+
+func load(t *testing.T, s sessions.Store, id string) *sessions.Record {
+	r, err := s.Load(id)
+	if err != nil {
+		t.Fatalf("Load(%s): %v", id, err)
+	}
+	return r
+}
+
+func save(t *testing.T, s sessions.Store, id string, r *sessions.Record) {
+	if err := s.Save(id, r); err != nil {
+		t.Fatalf("Save(%s): %v", id, err)
+	}
+}
+
+func sameTime(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d < time.Second && d > -time.Second
+}
+
+func testLoadMissing(t *testing.T, s sessions.Store) {
+	if r := load(t, s, id1); r != nil {
+		t.Fatal("Load of missing session returns a record")
+	}
+}
+
+func testSaveLoad(t *testing.T, s sessions.Store) {
+	save(t, s, id1, record(map[string]interface{}{"a": "1", "b": "2"}, time.Hour))
+	save(t, s, id2, record(map[string]interface{}{"a": "3"}, time.Hour))
+
+	r := load(t, s, id1)
+	if r == nil {
+		t.Fatal("saved session not found")
+	}
+	if len(r.Values) != 2 || r.Values["a"] != "1" || r.Values["b"] != "2" {
+		t.Fatalf("Load returns %v, want map[a:1 b:2]", r.Values)
+	}
+
+	r = load(t, s, id2)
+	if r == nil || len(r.Values) != 1 || r.Values["a"] != "3" {
+		t.Fatalf("Load returns %v, want map[a:3]", r)
+	}
+
+	save(t, s, id1, record(map[string]interface{}{}, time.Hour))
+	if r := load(t, s, id1); r == nil || len(r.Values) != 0 {
+		t.Fatalf("Load of empty session returns %v", r)
+	}
+}
+
+func testRecord(t *testing.T, s sessions.Store) {
+	now := time.Now()
+	want := &sessions.Record{
+		Values:     map[string]interface{}{},
+		CreatedAt:  now.Add(-time.Hour),
+		LastAccess: now.Add(-time.Minute),
+		Timeout:    2 * time.Hour,
+		Lifetime:   24 * time.Hour,
+		Principal:  "alice",
+	}
+	save(t, s, id1, want)
+
+	r := load(t, s, id1)
+	if r == nil {
+		t.Fatal("saved session not found")
+	}
+	if !sameTime(r.CreatedAt, want.CreatedAt) || !sameTime(r.LastAccess, want.LastAccess) {
+		t.Fatalf("Load returns times %v, %v, want %v, %v", r.CreatedAt, r.LastAccess, want.CreatedAt, want.LastAccess)
+	}
+	if r.Timeout != want.Timeout || r.Lifetime != want.Lifetime {
+		t.Fatalf("Load returns timeout %v, lifetime %v, want %v, %v", r.Timeout, r.Lifetime, want.Timeout, want.Lifetime)
+	}
+	if r.Principal != want.Principal {
+		t.Fatalf("Load returns principal %q, want %q", r.Principal, want.Principal)
+	}
+}
+
+func testOverwrite(t *testing.T, s sessions.Store) {
+	save(t, s, id1, record(map[string]interface{}{"a": "1", "b": "2"}, time.Hour))
+	save(t, s, id1, record(map[string]interface{}{"a": "x"}, time.Hour))
+
+	r := load(t, s, id1)
+	if r == nil || len(r.Values) != 1 || r.Values["a"] != "x" {
+		t.Fatalf("Load returns %v, want map[a:x]", r)
+	}
+}
+
+func testUpdate(t *testing.T, s sessions.Store) {
+	update := func(id string, fn func(r *sessions.Record) error) bool {
+		ok, err := s.Update(id, fn)
+		if err != nil {
+			t.Fatalf("Update(%s): %v", id, err)
+		}
+		return ok
+	}
+
+	if update(id1, func(r *sessions.Record) error { return nil }) {
+		t.Fatal("Update of missing session returns true")
+	}
+	if r := load(t, s, id1); r != nil {
+		t.Fatal("Update creates session")
+	}
+
+	save(t, s, id1, record(map[string]interface{}{"a": "1", "b": "2"}, time.Hour))
+	if !update(id1, func(r *sessions.Record) error {
+		if r.Values["b"] != "2" {
+			t.Errorf("Update passes %v, want the stored values", r.Values)
+		}
+		r.Values["a"] = "x"
+		delete(r.Values, "b")
+		r.Principal = "alice"
+		return nil
+	}) {
+		t.Fatal("Update of stored session returns false")
+	}
+	r := load(t, s, id1)
+	if r == nil || len(r.Values) != 1 || r.Values["a"] != "x" || r.Principal != "alice" {
+		t.Fatalf("Load returns %v after Update, want map[a:x] of alice", r)
+	}
+
+	errFail := errors.New("fail")
+	if _, err := s.Update(id1, func(r *sessions.Record) error {
+		r.Values["a"] = "y"
+		return errFail
+	}); !errors.Is(err, errFail) {
+		t.Fatalf("Update returns %v, want the error of fn", err)
+	}
+	if r := load(t, s, id1); r == nil || r.Values["a"] != "x" {
+		t.Fatalf("failed Update changes the session to %v", r)
+	}
+
+	expired := record(map[string]interface{}{"a": "1"}, time.Second)
+	expired.LastAccess = expired.LastAccess.Add(-2 * time.Second)
+	save(t, s, id2, expired)
+	if update(id2, func(r *sessions.Record) error { return nil }) {
+		t.Fatal("Update of expired session returns true")
+	}
+}
+
+func testLoadCopy(t *testing.T, s sessions.Store) {
+	r := record(map[string]interface{}{"a": "1"}, time.Hour)
+	save(t, s, id1, r)
+	r.Values["a"] = "changed"
+
+	loaded := load(t, s, id1)
+	if loaded.Values["a"] != "1" {
+		t.Fatal("Save keeps the caller's values")
+	}
+
+	loaded.Values["a"] = "changed"
+	loaded = load(t, s, id1)
+	if loaded.Values["a"] != "1" {
+		t.Fatal("Load returns the stored values")
+	}
+}
+
+func testDelete(t *testing.T, s sessions.Store) {
+	save(t, s, id1, record(map[string]interface{}{"a": "1"}, time.Hour))
+	save(t, s, id2, record(map[string]interface{}{"a": "2"}, time.Hour))
+
+	if ok, err := s.Delete(id1); err != nil || !ok {
+		t.Fatalf("Delete returns %v, %v, want true", ok, err)
+	}
+	if r := load(t, s, id1); r != nil {
+		t.Fatal("deleted session found")
+	}
+	if r := load(t, s, id2); r == nil {
+		t.Fatal("Delete removes other session")
+	}
+	if ok, err := s.Delete(id1); err != nil || ok {
+		t.Fatalf("Delete of missing session returns %v, %v, want false", ok, err)
+	}
+
+	expired := record(map[string]interface{}{"a": "1"}, time.Second)
+	expired.LastAccess = expired.LastAccess.Add(-2 * time.Second)
+	save(t, s, id1, expired)
+	if ok, err := s.Delete(id1); err != nil || ok {
+		t.Fatalf("Delete of expired session returns %v, %v, want false", ok, err)
+	}
+}
+
+func testExpire(t *testing.T, s sessions.Store) {
+	r := record(map[string]interface{}{"a": "1"}, time.Second)
+	r.LastAccess = r.LastAccess.Add(-2 * time.Second)
+	save(t, s, id1, r)
+	if r := load(t, s, id1); r != nil {
+		t.Fatal("expired session found")
+	}
+
+	save(t, s, id2, record(map[string]interface{}{"a": "1"}, 1500*time.Millisecond))
+	if r := load(t, s, id2); r == nil {
+		t.Fatal("session not found before expiry")
+	}
+	time.Sleep(2 * time.Second)
+	if r := load(t, s, id2); r != nil {
+		t.Fatal("session found after expiry")
+	}
+}
+
+func testLifetime(t *testing.T, s sessions.Store) {
+	r := record(map[string]interface{}{"a": "1"}, time.Hour)
+	r.Lifetime = 1500 * time.Millisecond
+	save(t, s, id1, r)
+
+	time.Sleep(time.Second)
+	if err := s.Touch(id1, time.Now()); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if r := load(t, s, id1); r == nil {
+		t.Fatal("session not found before end of lifetime")
+	}
+
+	time.Sleep(time.Second)
+	if r := load(t, s, id1); r != nil {
+		t.Fatal("session found after end of lifetime")
+	}
+}
+
+func testTouch(t *testing.T, s sessions.Store) {
+	save(t, s, id1, record(map[string]interface{}{"a": "1"}, 1500*time.Millisecond))
+	time.Sleep(time.Second)
+
+	now := time.Now()
+	if err := s.Touch(id1, now); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	time.Sleep(time.Second)
+
+	r := load(t, s, id1)
+	if r == nil {
+		t.Fatal("touched session expired")
+	}
+	if r.Values["a"] != "1" {
+		t.Fatalf("Touch changes values to %v", r.Values)
+	}
+	if !sameTime(r.LastAccess, now) {
+		t.Fatalf("LastAccess is %v after Touch, want %v", r.LastAccess, now)
+	}
+
+	if err := s.Touch(id2, time.Now()); err != nil {
+		t.Fatalf("Touch of missing session: %v", err)
+	}
+	if r := load(t, s, id2); r != nil {
+		t.Fatal("Touch creates session")
+	}
+}
+
+func testGC(t *testing.T, s sessions.Store) {
+	r := record(map[string]interface{}{"a": "1"}, time.Second)
+	r.LastAccess = r.LastAccess.Add(-2 * time.Second)
+	save(t, s, id1, r)
+	save(t, s, id2, record(map[string]interface{}{"a": "2"}, time.Hour))
+
+	if err := s.GC(); err != nil {
+		t.Fatalf("GC: %v", err)
+	}
+	if r := load(t, s, id1); r != nil {
+		t.Fatal("expired session found after GC")
+	}
+	if r := load(t, s, id2); r == nil {
+		t.Fatal("GC removes live session")
+	}
+}
+
+func testConcurrent(t *testing.T, s sessions.Store) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			id := fmt.Sprintf("%032x", i)
+			for j := 0; j < 20; j++ {
+				value := fmt.Sprint(j)
+				if err := s.Save(id, record(map[string]interface{}{"v": value}, time.Hour)); err != nil {
+					t.Errorf("Save(%s): %v", id, err)
+					return
+				}
+				r, err := s.Load(id)
+				if err != nil || r == nil || r.Values["v"] != value {
+					t.Errorf("Load(%s) returns %v, %v, want v=%s", id, r, err, value)
+					return
+				}
+				if err := s.Touch(id, time.Now()); err != nil {
+					t.Errorf("Touch(%s): %v", id, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func testConcurrentUpdate(t *testing.T, s sessions.Store) {
+	save(t, s, id1, record(map[string]interface{}{}, time.Hour))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			key := fmt.Sprint(i)
+			if _, err := s.Update(id1, func(r *sessions.Record) error {
+				r.Values[key] = key
+				return nil
+			}); err != nil {
+				t.Errorf("Update(%s): %v", id1, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if r := load(t, s, id1); r == nil || len(r.Values) != 16 {
+		t.Fatalf("Load returns %v after concurrent updates, want 16 values", r)
+	}
+}
+
+func testEnumerate(t *testing.T, s sessions.Store) {
+	es, ok := s.(sessions.EnumerableStore)
+	if !ok {
+		t.Skip("store is not enumerable")
+	}
+
+	expired := record(map[string]interface{}{"a": "0"}, time.Second)
+	expired.LastAccess = expired.LastAccess.Add(-2 * time.Second)
+	save(t, s, fmt.Sprintf("%032x", 0), expired)
+	save(t, s, id1, record(map[string]interface{}{"a": "1"}, time.Hour))
+	save(t, s, id2, record(map[string]interface{}{"a": "2"}, time.Hour))
+	if err := s.GC(); err != nil {
+		t.Fatalf("GC: %v", err)
+	}
+
+	if n, err := es.Count(); err != nil || n != 2 {
+		t.Fatalf("Count returns %d, %v, want 2", n, err)
+	}
+
+	values := make(map[string]interface{})
+	if err := es.Each(func(id string, r *sessions.Record) bool {
+		values[id] = r.Values["a"]
+		return true
+	}); err != nil {
+		t.Fatalf("Each: %v", err)
+	}
+	if len(values) != 2 || values[id1] != "1" || values[id2] != "2" {
+		t.Fatalf("Each visits %v, want %s:1 %s:2", values, id1, id2)
+	}
+
+	n := 0
+	if err := es.Each(func(id string, r *sessions.Record) bool {
+		n++
+		return false
+	}); err != nil || n != 1 {
+		t.Fatalf("Each visits %d sessions after returning false, %v", n, err)
+	}
+
+	if err := es.Each(func(id string, r *sessions.Record) bool {
+		if _, err := s.Delete(id); err != nil {
+			t.Errorf("Delete(%s) from Each: %v", id, err)
+		}
+		return true
+	}); err != nil {
+		t.Fatalf("Each: %v", err)
+	}
+	if n, err := es.Count(); err != nil || n != 0 {
+		t.Fatalf("Count returns %d, %v after deleting all sessions", n, err)
+	}
+}
+
+func testPrincipal(t *testing.T, s sessions.Store) {
+	is, ok := s.(sessions.IndexedStore)
+	if !ok {
+		t.Skip("store is not indexed")
+	}
+
+	id3 := fmt.Sprintf("%032x", 3)
+	for _, id := range []string{id1, id2, id3} {
+		r := record(map[string]interface{}{}, time.Hour)
+		r.Principal = "alice"
+		if id == id3 {
+			r.Principal = "bob"
+		}
+		save(t, s, id, r)
+	}
+
+	find := func(principal string) []string {
+		ids, err := is.FindByPrincipal(principal)
+		if err != nil {
+			t.Fatalf("FindByPrincipal(%s): %v", principal, err)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if ids := find("alice"); len(ids) != 2 || ids[0] != id1 || ids[1] != id2 {
+		t.Fatalf("FindByPrincipal(alice) returns %v, want [%s %s]", ids, id1, id2)
+	}
+
+	r := record(map[string]interface{}{}, time.Hour)
+	r.Principal = "bob"
+	save(t, s, id1, r)
+	if _, err := s.Delete(id3); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if ids := find("alice"); len(ids) != 1 || ids[0] != id2 {
+		t.Fatalf("FindByPrincipal(alice) returns %v after overwrite, want [%s]", ids, id2)
+	}
+	if ids := find("bob"); len(ids) != 1 || ids[0] != id1 {
+		t.Fatalf("FindByPrincipal(bob) returns %v after delete, want [%s]", ids, id1)
+	}
+	if ids := find("carol"); len(ids) != 0 {
+		t.Fatalf("FindByPrincipal(carol) returns %v", ids)
+	}
+}
@@ -0,0 +1,395 @@
+package gmvc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultMaxValueSize = 1 << 20 // 1 MB
+	sniffLen            = 512
+)
+
+type UploadOptions struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+	MaxValueSize int64
+	MaxMemory    int64  // files larger than it are spooled to disk by ReadForm
+	TempDir      string // directory of the spooled files, default os.TempDir()
+	AllowedTypes []string
+}
+
+type Uploads struct {
+	Values Values
+
+	reader  *multipart.Reader
+	options UploadOptions
+}
+
+func (c *Context) Uploads(options *UploadOptions) (*Uploads, error) {
+	var opts UploadOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.MaxValueSize <= 0 {
+		opts.MaxValueSize = defaultMaxValueSize
+	}
+	if opts.MaxMemory <= 0 {
+		opts.MaxMemory = defaultMaxMemory
+	}
+
+	c.LimitBody(opts.MaxTotalSize)
+
+	r, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, NewStatusError(http.StatusBadRequest, err)
+	}
+
+	return &Uploads{
+		Values:  make(Values),
+		reader:  r,
+		options: opts,
+	}, nil
+}
+
+func (u *Uploads) Next() (*UploadFile, error) {
+	for {
+		part, err := u.reader.NextPart()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, uploadError(err)
+		}
+
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			if err := u.readValue(name, part); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return u.newFile(name, part)
+	}
+}
+
+func (u *Uploads) readValue(name string, part *multipart.Part) error {
+	defer part.Close()
+
+	b, err := io.ReadAll(io.LimitReader(part, u.options.MaxValueSize+1))
+	if err != nil {
+		return uploadError(err)
+	}
+	if int64(len(b)) > u.options.MaxValueSize {
+		return NewStatusError(http.StatusRequestEntityTooLarge, fmt.Errorf("form value '%s' too large, limit %d bytes", name, u.options.MaxValueSize))
+	}
+
+	u.Values.Add(name, string(b))
+	return nil
+}
+
+func (u *Uploads) newFile(name string, part *multipart.Part) (*UploadFile, error) {
+	f := &UploadFile{
+		FieldName: name,
+		FileName:  SanitizeFilename(part.FileName()),
+		Header:    part.Header,
+		part:      part,
+		limit:     u.options.MaxFileSize,
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, uploadError(err)
+	}
+	head = head[:n]
+
+	f.ContentType = http.DetectContentType(head)
+	if !u.allowType(f.ContentType) {
+		part.Close()
+		return nil, NewStatusError(http.StatusUnsupportedMediaType, fmt.Errorf("file '%s' has disallowed type %s", f.FileName, f.ContentType))
+	}
+
+	f.r = io.MultiReader(bytes.NewReader(head), part)
+	if f.limit > 0 {
+		f.r = io.LimitReader(f.r, f.limit+1)
+	}
+	return f, nil
+}
+
+func (u *Uploads) ReadForm() (*UploadForm, error) {
+	form := &UploadForm{
+		Values: u.Values,
+		Files:  make(map[string][]*SpooledFile),
+	}
+
+	for {
+		f, err := u.Next()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			form.RemoveAll()
+			return nil, err
+		}
+
+		sf, err := f.spool(u.options.MaxMemory, u.options.TempDir)
+		if err != nil {
+			form.RemoveAll()
+			return nil, err
+		}
+		form.Files[sf.FieldName] = append(form.Files[sf.FieldName], sf)
+	}
+}
+
+func (u *Uploads) allowType(contentType string) bool {
+	if len(u.options.AllowedTypes) == 0 {
+		return true
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range u.options.AllowedTypes {
+		t = strings.ToLower(t)
+		if t == mt || t == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(mt, t[:len(t)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type UploadFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+	Size        int64
+
+	part  *multipart.Part
+	r     io.Reader
+	limit int64
+}
+
+func (f *UploadFile) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if f.limit > 0 && f.Size+int64(n) > f.limit {
+		n = int(f.limit - f.Size)
+		f.Size = f.limit
+		return n, NewStatusError(http.StatusRequestEntityTooLarge, fmt.Errorf("file '%s' too large, limit %d bytes", f.FileName, f.limit))
+	}
+	f.Size += int64(n)
+
+	if err != nil && err != io.EOF {
+		return n, uploadError(err)
+	}
+
+	return n, err
+}
+
+func (f *UploadFile) Close() error {
+	return f.part.Close()
+}
+
+func (f *UploadFile) SaveTo(dir string) (string, error) {
+	defer f.Close()
+
+	ext := filepath.Ext(f.FileName)
+	base := strings.TrimSuffix(f.FileName, ext)
+
+	var (
+		file *os.File
+		p    string
+		err  error
+	)
+	for i := 0; i < 100; i++ {
+		name := f.FileName
+		if i > 0 {
+			name = base + "-" + strconv.Itoa(i) + ext
+		}
+
+		p = filepath.Join(dir, name)
+		file, err = os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil || !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, f); err != nil {
+		file.Close()
+		os.Remove(p)
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(p)
+		return "", err
+	}
+
+	return p, nil
+}
+
+func (f *UploadFile) spool(maxMemory int64, dir string) (*SpooledFile, error) {
+	defer f.Close()
+
+	sf := &SpooledFile{
+		FieldName:   f.FieldName,
+		FileName:    f.FileName,
+		ContentType: f.ContentType,
+		Header:      f.Header,
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, f, maxMemory+1); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if int64(buf.Len()) <= maxMemory {
+		sf.content = buf.Bytes()
+		sf.Size = f.Size
+		return sf, nil
+	}
+
+	file, err := os.CreateTemp(dir, "gmvc-upload-")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(file, io.MultiReader(&buf, f))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	sf.tmpfile = file.Name()
+	sf.Size = f.Size
+	return sf, nil
+}
+
+type UploadForm struct {
+	Values Values
+	Files  map[string][]*SpooledFile
+}
+
+func (f *UploadForm) File(key string) *SpooledFile {
+	if fs := f.Files[key]; len(fs) > 0 {
+		return fs[0]
+	}
+	return nil
+}
+
+func (f *UploadForm) RemoveAll() error {
+	var err error
+	for _, fs := range f.Files {
+		for _, sf := range fs {
+			if sf.tmpfile == "" {
+				continue
+			}
+			if e := os.Remove(sf.tmpfile); e != nil && !os.IsNotExist(e) && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+type SpooledFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+	Size        int64
+
+	content []byte
+	tmpfile string
+}
+
+func (f *SpooledFile) Open() (multipart.File, error) {
+	if f.tmpfile != "" {
+		return os.Open(f.tmpfile)
+	}
+	return sectionReadCloser{io.NewSectionReader(bytes.NewReader(f.content), 0, int64(len(f.content)))}, nil
+}
+
+func (f *SpooledFile) OnDisk() bool {
+	return f.tmpfile != ""
+}
+
+type sectionReadCloser struct {
+	*io.SectionReader
+}
+
+func (sectionReadCloser) Close() error {
+	return nil
+}
+
+func SanitizeFilename(name string) string {
+	name = strings.Replace(name, "\\", "/", -1)
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		name = name[i+1:]
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			return r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		case unicode.IsSpace(r):
+			return '_'
+		}
+		return -1
+	}, name)
+
+	name = strings.TrimLeft(name, ".")
+	if rs := []rune(name); len(rs) > 200 {
+		ext := []rune(filepath.Ext(name))
+		if len(ext) > 20 {
+			ext = nil
+		}
+		name = string(rs[:200-len(ext)]) + string(ext)
+	}
+
+	if name == "" {
+		return "upload"
+	}
+	return name
+}
+
+func uploadError(err error) error {
+	if e := bodyError(err); e != err {
+		return e
+	}
+	var se StatusError
+	if errors.As(err, &se) {
+		return err
+	}
+	return NewStatusError(http.StatusBadRequest, err)
+}
@@ -0,0 +1,271 @@
+package gmvc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var pngHeader = "\x89PNG\x0D\x0A\x1A\x0A"
+
+type uploadPart struct {
+	name     string
+	filename string
+	content  string
+}
+
+func newUploadRequest(parts ...uploadPart) *http.Request {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	for _, p := range parts {
+		if p.filename == "" {
+			mw.WriteField(p.name, p.content)
+			continue
+		}
+		fw, _ := mw.CreateFormFile(p.name, p.filename)
+		io.WriteString(fw, p.content)
+	}
+	mw.Close()
+
+	return newFormRequest("/upload", mw.FormDataContentType(), buf.String())
+}
+
+func serveUploads(t *testing.T, r *http.Request, maxBodySize int64, h func(c *Context) error) int {
+	t.Helper()
+
+	app := NewApp()
+	app.MaxBodySize = maxBodySize
+	app.Router.HandleFunc("/upload", h)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestUploads(t *testing.T) {
+	dir := t.TempDir()
+	r := newUploadRequest(
+		uploadPart{"title", "", "photos"},
+		uploadPart{"file", "../../a b.png", pngHeader + "image"},
+		uploadPart{"file", "a b.png", pngHeader + "again"},
+	)
+
+	var names []string
+	var types []string
+	var title string
+	status := serveUploads(t, r, 0, func(c *Context) error {
+		u, err := c.Uploads(&UploadOptions{AllowedTypes: []string{"image/*"}})
+		if err != nil {
+			return err
+		}
+		for {
+			f, err := u.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			types = append(types, f.ContentType)
+			p, err := f.SaveTo(dir)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.Base(p))
+		}
+		title = u.Values.Get("title")
+		return nil
+	})
+
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if title != "photos" {
+		t.Errorf("title = %q, want photos", title)
+	}
+	if strings.Join(names, ",") != "a_b.png,a_b-1.png" {
+		t.Errorf("saved = %v", names)
+	}
+	if strings.Join(types, ",") != "image/png,image/png" {
+		t.Errorf("types = %v", types)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "a_b-1.png")); string(b) != pngHeader+"again" {
+		t.Errorf("content = %q", b)
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     UploadOptions
+		maxBodySize int64
+		parts       []uploadPart
+		status      int
+	}{
+		{"within limits", UploadOptions{MaxFileSize: 10}, 0, []uploadPart{{"f", "a.txt", "0123456789"}}, http.StatusOK},
+		{"file too large", UploadOptions{MaxFileSize: 10}, 0, []uploadPart{{"f", "a.txt", "0123456789x"}}, http.StatusRequestEntityTooLarge},
+		{"value too large", UploadOptions{MaxValueSize: 3}, 0, []uploadPart{{"v", "", "abcd"}}, http.StatusRequestEntityTooLarge},
+		{"total too large", UploadOptions{MaxTotalSize: 100}, 0, []uploadPart{{"f", "a.txt", strings.Repeat("a", 200)}}, http.StatusRequestEntityTooLarge},
+		{"total can not raise", UploadOptions{MaxTotalSize: 1 << 20}, 200, []uploadPart{{"f", "a.txt", strings.Repeat("a", 300)}}, http.StatusRequestEntityTooLarge},
+		{"disallowed type", UploadOptions{AllowedTypes: []string{"image/png"}}, 0, []uploadPart{{"f", "a.png", "plain text"}}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		status := serveUploads(t, newUploadRequest(tt.parts...), tt.maxBodySize, func(c *Context) error {
+			u, err := c.Uploads(&tt.options)
+			if err != nil {
+				return err
+			}
+			for {
+				f, err := u.Next()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(io.Discard, f); err != nil {
+					return err
+				}
+			}
+		})
+		if status != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, status, tt.status)
+		}
+	}
+}
+
+func TestUploadFileLimit(t *testing.T) {
+	r := newUploadRequest(uploadPart{"f", "a.txt", "0123456789abcdef"})
+
+	var got []byte
+	var err error
+	serveUploads(t, r, 0, func(c *Context) error {
+		u, _ := c.Uploads(&UploadOptions{MaxFileSize: 10})
+		f, _ := u.Next()
+		got, err = io.ReadAll(f)
+		return nil
+	})
+
+	var se StatusError
+	if !errors.As(err, &se) || se.Status() != http.StatusRequestEntityTooLarge {
+		t.Errorf("ReadAll returns %v, want status 413", err)
+	}
+	if string(got) != "0123456789" {
+		t.Errorf("read %q, want the first 10 bytes", got)
+	}
+}
+
+func TestUploadReadForm(t *testing.T) {
+	dir := t.TempDir()
+	large := strings.Repeat("b", 100)
+	r := newUploadRequest(
+		uploadPart{"title", "", "docs"},
+		uploadPart{"small", "s.txt", "small"},
+		uploadPart{"large", "l.txt", large},
+	)
+
+	var form *UploadForm
+	status := serveUploads(t, r, 0, func(c *Context) error {
+		u, err := c.Uploads(&UploadOptions{MaxMemory: 10, TempDir: dir})
+		if err != nil {
+			return err
+		}
+		form, err = u.ReadForm()
+		return err
+	})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+
+	if form.Values.Get("title") != "docs" {
+		t.Errorf("title = %q", form.Values.Get("title"))
+	}
+
+	for _, tt := range []struct {
+		key     string
+		content string
+		onDisk  bool
+	}{
+		{"small", "small", false},
+		{"large", large, true},
+	} {
+		sf := form.File(tt.key)
+		if sf == nil {
+			t.Errorf("%s: no file", tt.key)
+			continue
+		}
+		if sf.OnDisk() != tt.onDisk || sf.Size != int64(len(tt.content)) {
+			t.Errorf("%s: on disk %v, size %d", tt.key, sf.OnDisk(), sf.Size)
+		}
+		f, err := sf.Open()
+		if err != nil {
+			t.Errorf("%s: Open: %v", tt.key, err)
+			continue
+		}
+		b, _ := io.ReadAll(f)
+		f.Close()
+		if string(b) != tt.content {
+			t.Errorf("%s: content = %q", tt.key, b)
+		}
+	}
+
+	if err := form.RemoveAll(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("%d spooled files left after RemoveAll", len(files))
+	}
+}
+
+func TestUploadReadFormError(t *testing.T) {
+	dir := t.TempDir()
+	r := newUploadRequest(
+		uploadPart{"a", "a.txt", strings.Repeat("a", 50)},
+		uploadPart{"b", "b.txt", strings.Repeat("b", 200)},
+	)
+
+	status := serveUploads(t, r, 0, func(c *Context) error {
+		u, err := c.Uploads(&UploadOptions{MaxMemory: 10, MaxFileSize: 100, TempDir: dir})
+		if err != nil {
+			return err
+		}
+		_, err = u.ReadForm()
+		return err
+	})
+
+	if status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", status)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("%d spooled files left after an error", len(files))
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a.txt", "a.txt"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\dir\a.txt`, "a.txt"},
+		{"..hidden", "hidden"},
+		{"a b<>.txt", "a_b.txt"},
+		{"", "upload"},
+		{"...", "upload"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeFilename(tt.name); got != tt.want {
+			t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
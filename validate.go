@@ -0,0 +1,422 @@
+package gmvc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	validateTagNames = []string{"form", "json", "query", "path"}
+)
+
+var (
+	rulesMutex sync.RWMutex
+	rules      = map[string]Rule{
+		"min":   ruleMin,
+		"max":   ruleMax,
+		"len":   ruleLen,
+		"email": ruleEmail,
+		"oneof": ruleOneof,
+	}
+	rulesCache sync.Map
+)
+
+type Rule func(v reflect.Value, param string) error
+
+func RegisterRule(name string, rule Rule) {
+	rulesMutex.Lock()
+	defer rulesMutex.Unlock()
+	rules[name] = rule
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	ss := make([]string, len(e))
+	for i, fe := range e {
+		ss[i] = fe.Error()
+	}
+	return strings.Join(ss, "; ")
+}
+
+func (e ValidationErrors) Status() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e ValidationErrors) Has(field string) bool {
+	return e.Get(field) != ""
+}
+
+func (e ValidationErrors) Get(field string) string {
+	for _, fe := range e {
+		if fe.Field == field {
+			return fe.Message
+		}
+	}
+	return ""
+}
+
+func (e ValidationErrors) Map() map[string]string {
+	m := make(map[string]string, len(e))
+	for _, fe := range e {
+		if _, ok := m[fe.Field]; !ok {
+			m[fe.Field] = fe.Message
+		}
+	}
+	return m
+}
+
+func Validate(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if err := validateStruct(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type fieldRules struct {
+	index    int
+	name     string
+	required bool
+	checks   []*fieldCheck
+}
+
+type fieldCheck struct {
+	rule  string
+	param string
+	fn    Rule
+}
+
+func structRules(t reflect.Type) ([]*fieldRules, error) {
+	if fields, ok := rulesCache.Load(t); ok {
+		return fields.([]*fieldRules), nil
+	}
+
+	var fields []*fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+
+		fr := &fieldRules{index: i, name: fieldName(sf)}
+		if sf.Anonymous {
+			fr.name = ""
+		}
+
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			if err := fr.parse(sf.Type, tag); err != nil {
+				return nil, fmt.Errorf("validate %s.%s: %v", t, sf.Name, err)
+			}
+		}
+		fields = append(fields, fr)
+	}
+
+	rulesCache.Store(t, fields)
+	return fields, nil
+}
+
+func (fr *fieldRules) parse(t reflect.Type, tag string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, item := range splitTag(tag) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if item == "required" {
+			fr.required = true
+			continue
+		}
+
+		var rule, param string
+		if i := strings.Index(item, "="); i != -1 {
+			rule, param = item[:i], item[i+1:]
+		} else {
+			rule = item
+		}
+
+		fn, err := prepareRule(rule, param, t)
+		if err != nil {
+			return err
+		}
+		fr.checks = append(fr.checks, &fieldCheck{rule: rule, param: param, fn: fn})
+	}
+	return nil
+}
+
+func splitTag(tag string) []string {
+	var items []string
+	var b strings.Builder
+
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			b.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			items = append(items, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(tag[i])
+		}
+	}
+	return append(items, b.String())
+}
+
+func prepareRule(rule string, param string, t reflect.Type) (Rule, error) {
+	switch rule {
+	case "min", "max", "len":
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return nil, fmt.Errorf("bad param '%s' of rule %s", param, rule)
+		}
+		if !isMeasurable(t) {
+			return nil, fmt.Errorf("rule %s can not measure %s", rule, t)
+		}
+
+	case "oneof":
+		if strings.TrimSpace(param) == "" {
+			return nil, errors.New("rule oneof without values")
+		}
+
+	case "regexp":
+		regex, err := regexp.Compile(param)
+		if err != nil {
+			return nil, fmt.Errorf("bad regexp '%s': %v", param, err)
+		}
+		return func(v reflect.Value, param string) error {
+			if !regex.MatchString(fmt.Sprint(v.Interface())) {
+				return errors.New("has invalid format")
+			}
+			return nil
+		}, nil
+	}
+
+	rulesMutex.RLock()
+	r := rules[rule]
+	rulesMutex.RUnlock()
+	if r == nil {
+		return nil, fmt.Errorf("unknown validation rule '%s'", rule)
+	}
+	return r, nil
+}
+
+func validateStruct(v reflect.Value, prefix string, errs *ValidationErrors) error {
+	fields, err := structRules(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, fr := range fields {
+		fv := v.Field(fr.index)
+		name := prefix + fr.name
+		if fr.name == "" {
+			name = strings.TrimSuffix(prefix, ".")
+		}
+
+		fr.validate(fv, name, errs)
+
+		if err := validateNested(fv, name, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateNested(v reflect.Value, name string, errs *ValidationErrors) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == typeOfTime {
+			return nil
+		}
+		prefix := ""
+		if name != "" {
+			prefix = name + "."
+		}
+		return validateStruct(v, prefix, errs)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateNested(v.Index(i), name+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (fr *fieldRules) validate(v reflect.Value, name string, errs *ValidationErrors) {
+	if isZero(v) {
+		if fr.required {
+			*errs = append(*errs, &FieldError{Field: name, Rule: "required", Message: "is required"})
+		}
+		return
+	}
+
+	v = reflect.Indirect(v)
+
+	for _, c := range fr.checks {
+		if err := c.fn(v, c.param); err != nil {
+			*errs = append(*errs, &FieldError{Field: name, Rule: c.rule, Param: c.param, Message: err.Error()})
+			return
+		}
+	}
+}
+
+func fieldName(sf reflect.StructField) string {
+	for _, tn := range validateTagNames {
+		name := sf.Tag.Get(tn)
+		if i := strings.Index(name, ","); i != -1 {
+			name = name[:i]
+		}
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+func size(v reflect.Value, param string) (float64, float64, error) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad param '%s': %v", param, err)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return float64(len([]rune(v.String()))), n, nil
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), n, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), n, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), n, nil
+	}
+
+	return 0, 0, fmt.Errorf("can not measure %s", v.Type())
+}
+
+func isMeasurable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return isNumber(reflect.Zero(t))
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func ruleMin(v reflect.Value, param string) error {
+	n, min, err := size(v, param)
+	if err != nil {
+		return err
+	}
+	if n < min {
+		if isNumber(v) {
+			return fmt.Errorf("must be at least %s", param)
+		}
+		return fmt.Errorf("length must be at least %s", param)
+	}
+	return nil
+}
+
+func ruleMax(v reflect.Value, param string) error {
+	n, max, err := size(v, param)
+	if err != nil {
+		return err
+	}
+	if n > max {
+		if isNumber(v) {
+			return fmt.Errorf("must be at most %s", param)
+		}
+		return fmt.Errorf("length must be at most %s", param)
+	}
+	return nil
+}
+
+func ruleLen(v reflect.Value, param string) error {
+	n, l, err := size(v, param)
+	if err != nil {
+		return err
+	}
+	if n != l {
+		return fmt.Errorf("length must be %s", param)
+	}
+	return nil
+}
+
+func ruleEmail(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	a, err := mail.ParseAddress(s)
+	if err != nil || a.Address != s {
+		return errors.New("must be a valid email address")
+	}
+	return nil
+}
+
+func ruleOneof(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	for _, o := range strings.Fields(param) {
+		if s == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of [%s]", param)
+}
@@ -0,0 +1,126 @@
+package gmvc
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type validateAddress struct {
+	City string `form:"city" validate:"required"`
+}
+
+type validateForm struct {
+	Name    string            `form:"name" validate:"required,min=3,max=5"`
+	Email   string            `form:"email" validate:"email"`
+	Role    string            `form:"role" validate:"oneof=admin user"`
+	Code    string            `form:"code" validate:"regexp=^[a-z]{2\\,3}$,len=3"`
+	Age     *int              `form:"age" validate:"min=18"`
+	Tags    []string          `form:"tag" validate:"max=2"`
+	Address validateAddress   `form:"addr"`
+	Items   []validateAddress `form:"items"`
+}
+
+func TestValidate(t *testing.T) {
+	age := 16
+
+	tests := []struct {
+		name string
+		form validateForm
+		want []string
+	}{
+		{"valid", validateForm{Name: "bob", Address: validateAddress{City: "Rome"}}, nil},
+		{"required", validateForm{}, []string{"name required", "addr.city required"}},
+		{"min", validateForm{Name: "bo", Address: validateAddress{City: "Rome"}}, []string{"name min"}},
+		{"max", validateForm{Name: "bobbyb", Address: validateAddress{City: "Rome"}}, []string{"name max"}},
+		{"email", validateForm{Name: "bob", Email: "bob", Address: validateAddress{City: "Rome"}}, []string{"email email"}},
+		{"oneof", validateForm{Name: "bob", Role: "root", Address: validateAddress{City: "Rome"}}, []string{"role oneof"}},
+		{"escaped regexp", validateForm{Name: "bob", Code: "abc", Address: validateAddress{City: "Rome"}}, nil},
+		{"regexp", validateForm{Name: "bob", Code: "a1", Address: validateAddress{City: "Rome"}}, []string{"code regexp"}},
+		{"rule after regexp", validateForm{Name: "bob", Code: "ab", Address: validateAddress{City: "Rome"}}, []string{"code len"}},
+		{"pointer", validateForm{Name: "bob", Age: &age, Address: validateAddress{City: "Rome"}}, []string{"age min"}},
+		{"slice", validateForm{Name: "bob", Tags: []string{"a", "b", "c"}, Address: validateAddress{City: "Rome"}}, []string{"tag max"}},
+		{"nested slice", validateForm{Name: "bob", Address: validateAddress{City: "Rome"}, Items: []validateAddress{{City: "a"}, {}}},
+			[]string{"items[1].city required"}},
+	}
+
+	for _, tt := range tests {
+		err := Validate(&tt.form)
+
+		var got []string
+		if err != nil {
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Errorf("%s: Validate returns %v, want ValidationErrors", tt.name, err)
+				continue
+			}
+			if errs.Status() != http.StatusUnprocessableEntity {
+				t.Errorf("%s: status = %d", tt.name, errs.Status())
+			}
+			for _, fe := range errs {
+				got = append(got, fe.Field+" "+fe.Rule)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMisconfigured(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"bad param", &struct {
+			Name string `validate:"min=abc"`
+		}{Name: "x"}},
+		{"unmeasurable", &struct {
+			Ok bool `validate:"max=1"`
+		}{}},
+		{"bad regexp", &struct {
+			Code string `validate:"regexp=[a-"`
+		}{}},
+		{"unknown rule", &struct {
+			Code string `validate:"nope"`
+		}{}},
+		{"nested", &struct {
+			Inner struct {
+				Name string `validate:"len=x"`
+			}
+		}{}},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.v)
+		if err == nil {
+			t.Errorf("%s: Validate returns no error", tt.name)
+			continue
+		}
+
+		var ve ValidationErrors
+		var se StatusError
+		if errors.As(err, &ve) || errors.As(err, &se) {
+			t.Errorf("%s: Validate returns %T, want a plain error", tt.name, err)
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("even", func(v reflect.Value, param string) error {
+		if v.Int()%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+
+	form := struct {
+		N int `validate:"even"`
+	}{N: 3}
+
+	errs, ok := Validate(&form).(ValidationErrors)
+	if !ok || len(errs) != 1 || errs[0].Rule != "even" || errs[0].Message != "must be even" {
+		t.Errorf("Validate = %v, want even error", errs)
+	}
+}
@@ -210,31 +210,38 @@ func (w *buffer) flush() error {
 	return err
 }
 
-var pageFuncs template.FuncMap = template.FuncMap{
-	"import": func(pc *pageContext, args ...interface{}) (string, error) {
-		ac := len(args)
-		if ac == 0 || ac > 2 {
-			fmt.Errorf("wrong number of args for import: want 1 or 2 got %s", ac)
-		}
-		name := fmt.Sprint(args[0])
-		subpc := pc.sub()
-		if ac > 1 {
-			subpc.Data = args[1]
-		}
-		return "", pc.view.render(pc, name)
-	},
-	"include": func(pc *pageContext, urlpath string) (string, error) {
-		return "", pc.Context.Include(urlpath)
-	},
-	"session": func(pc *pageContext, args ...bool) (gmvc.Session, error) {
-		ac := len(args)
-		if ac > 1 {
-			return nil, fmt.Errorf("wrong number of args for session: want 0 or 1 got %s", ac)
-		}
-		var create bool
-		if ac == 1 {
-			create = args[0]
-		}
-		return pc.Context.Session(create)
-	},
+var pageFuncs template.FuncMap
+
+func init() {
+	pageFuncs = template.FuncMap{
+		"import": func(pc *pageContext, args ...interface{}) (string, error) {
+			ac := len(args)
+			if ac == 0 || ac > 2 {
+				return "", fmt.Errorf("wrong number of args for import: want 1 or 2 got %d", ac)
+			}
+			name := fmt.Sprint(args[0])
+			subpc := pc.sub()
+			if ac > 1 {
+				subpc.Data = args[1]
+			}
+			return "", pc.view.render(subpc, name)
+		},
+		"include": func(pc *pageContext, urlpath string) (string, error) {
+			return "", pc.Context.Include(urlpath)
+		},
+		"session": func(pc *pageContext, args ...bool) (gmvc.Session, error) {
+			ac := len(args)
+			if ac > 1 {
+				return nil, fmt.Errorf("wrong number of args for session: want 0 or 1 got %d", ac)
+			}
+			var create bool
+			if ac == 1 {
+				create = args[0]
+			}
+			return pc.Context.Session(create)
+		},
+		"flashes": func(pc *pageContext, kinds ...string) ([]gmvc.FlashMessage, error) {
+			return pc.Context.Flash().PopAll(kinds...)
+		},
+	}
 }